@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// errorType is the reflect.Type of the error interface
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// adaptFunc adapts any func that accepts numIn args and returns numOut results, optionally followed by an error, into a
+// func that accepts and returns interface{} values. If result is not nil, the first result must be that type.
+// The adapted func converts each arg to the parameter type, and returns the results and any error, including an error
+// converting an arg.
+// Panics with the given message if f does not have the required signature.
+func adaptFunc(
+	f interface{},
+	numIn, numOut int,
+	result reflect.Type,
+	msg string,
+) func(args ...interface{}) ([]interface{}, error) {
+	val := reflect.ValueOf(f)
+	if val.Kind() != reflect.Func {
+		panic(msg)
+	}
+
+	typ := val.Type()
+	if (typ.NumIn() != numIn) || typ.IsVariadic() {
+		panic(msg)
+	}
+
+	hasErr := typ.NumOut() == numOut+1
+	if (!hasErr) && (typ.NumOut() != numOut) {
+		panic(msg)
+	}
+
+	if hasErr && (typ.Out(numOut) != errorType) {
+		panic(msg)
+	}
+
+	if (result != nil) && (typ.Out(0) != result) {
+		panic(msg)
+	}
+
+	return func(args ...interface{}) ([]interface{}, error) {
+		in := make([]reflect.Value, numIn)
+		for i, arg := range args {
+			var err error
+			if in[i], err = convertTo(arg, typ.In(i)); err != nil {
+				return nil, fmt.Errorf("arg %d: %w", i, err)
+			}
+		}
+
+		var (
+			out     = val.Call(in)
+			results = make([]interface{}, numOut)
+		)
+
+		for i := range results {
+			results[i] = out[i].Interface()
+		}
+
+		if hasErr {
+			if err := out[numOut].Interface(); err != nil {
+				return nil, err.(error)
+			}
+		}
+
+		return results, nil
+	}
+}
+
+// FilterOf adapts any func that accepts a single value of any type and returns a bool, optionally followed by an error,
+// into a func(interface{}) bool suitable for Filter, without needing a type assertion.
+// The element is converted to the parameter type. If the conversion fails or f returns an error, the adapted func
+// panics with the error.
+// Panics if f does not have the required signature.
+func FilterOf(f interface{}) func(element interface{}) bool {
+	fn := adaptFunc(f, 1, 1, reflect.TypeOf(true), "f must be a func of one arg that returns a bool, optionally followed by an error")
+
+	return func(element interface{}) bool {
+		results, err := fn(element)
+		if err != nil {
+			panic(err)
+		}
+
+		return results[0].(bool)
+	}
+}
+
+// MapOf adapts any func that accepts a single value of any type and returns a single value of any type, optionally
+// followed by an error, into a func(interface{}) interface{} suitable for Map, without needing a type assertion.
+// The element is converted to the parameter type. If the conversion fails or f returns an error, the adapted func
+// panics with the error. Use MapErrOf to handle errors with MapOrElse or MapWithCircuitBreaker instead.
+// Panics if f does not have the required signature.
+func MapOf(f interface{}) func(element interface{}) interface{} {
+	fn := MapErrOf(f)
+
+	return func(element interface{}) interface{} {
+		result, err := fn(element)
+		if err != nil {
+			panic(err)
+		}
+
+		return result
+	}
+}
+
+// MapErrOf is like MapOf, except that the adapted func returns the error of a failed conversion or of f, so that it is
+// suitable for MapOrElse and MapWithCircuitBreaker.
+// Panics if f does not have the required signature.
+func MapErrOf(f interface{}) func(element interface{}) (interface{}, error) {
+	fn := adaptFunc(f, 1, 1, nil, "f must be a func of one arg that returns one value, optionally followed by an error")
+
+	return func(element interface{}) (interface{}, error) {
+		results, err := fn(element)
+		if err != nil {
+			return nil, err
+		}
+
+		return results[0], nil
+	}
+}
+
+// ConsumerOf adapts any func that accepts a single value of any type and returns nothing or an error into a
+// func(interface{}) suitable for ForEach, without needing a type assertion.
+// The element is converted to the parameter type. If the conversion fails or f returns an error, the adapted func
+// panics with the error.
+// Panics if f does not have the required signature.
+func ConsumerOf(f interface{}) func(element interface{}) {
+	fn := adaptFunc(f, 1, 0, nil, "f must be a func of one arg that returns nothing or an error")
+
+	return func(element interface{}) {
+		if _, err := fn(element); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ReduceOf adapts any func that accepts an accumulator and an element of any types and returns an accumulator of the
+// same type, optionally followed by an error, into a func(interface{}, interface{}) interface{} suitable for Reduce,
+// without needing type assertions.
+// The accumulator and element are converted to the parameter types. If a conversion fails or f returns an error, the
+// adapted func panics with the error.
+// Panics if f does not have the required signature.
+func ReduceOf(f interface{}) func(accumulator interface{}, element interface{}) interface{} {
+	const msg = "f must be a func of two args that returns a value of the first arg type, optionally followed by an error"
+
+	var result reflect.Type
+	if typ := reflect.TypeOf(f); (typ != nil) && (typ.Kind() == reflect.Func) && (typ.NumIn() > 0) {
+		result = typ.In(0)
+	}
+
+	fn := adaptFunc(f, 2, 1, result, msg)
+
+	return func(accumulator interface{}, element interface{}) interface{} {
+		results, err := fn(accumulator, element)
+		if err != nil {
+			panic(err)
+		}
+
+		return results[0]
+	}
+}
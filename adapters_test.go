@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterOf(t *testing.T) {
+	even := FilterOf(func(i int) bool { return i%2 == 0 })
+	assert.Equal(t, []interface{}{2, int8(4)}, Of(1, 2, 3, int8(4)).Filter(even).AndThen().ToSlice())
+
+	errOdd := errors.New("odd")
+	evenOrFail := FilterOf(func(i int) (bool, error) {
+		if i%2 == 1 {
+			return false, errOdd
+		}
+		return true, nil
+	})
+	assert.True(t, evenOrFail(2))
+
+	func() {
+		defer func() {
+			assert.Equal(t, errOdd, recover())
+		}()
+
+		evenOrFail(1)
+		assert.Fail(t, "Must panic")
+	}()
+
+	func() {
+		defer func() {
+			assert.EqualError(t, recover().(error), "arg 0: type string is not convertible to int")
+		}()
+
+		even("a")
+		assert.Fail(t, "Must panic")
+	}()
+
+	for _, f := range []interface{}{nil, 1, func(int) int { return 0 }, func(int) (bool, int) { return false, 0 }, func(...int) bool { return false }} {
+		func() {
+			defer func() {
+				assert.Equal(t, "f must be a func of one arg that returns a bool, optionally followed by an error", recover())
+			}()
+
+			FilterOf(f)
+			assert.Fail(t, "Must panic")
+		}()
+	}
+}
+
+func TestMapOf(t *testing.T) {
+	itoa := MapOf(strconv.Itoa)
+	assert.Equal(t, []interface{}{"1", "2"}, Of(1, 2).Map(itoa).AndThen().ToSlice())
+
+	atoi := MapOf(strconv.Atoi)
+	assert.Equal(t, []interface{}{1, 2}, Of("1", "2").Map(atoi).AndThen().ToSlice())
+
+	func() {
+		defer func() {
+			assert.NotNil(t, recover())
+		}()
+
+		atoi("a")
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Errors route into MapOrElse
+	assert.Equal(
+		t,
+		[]interface{}{1, -1},
+		Of("1", "a").MapOrElse(MapErrOf(strconv.Atoi), func(interface{}, error) interface{} { return -1 }).AndThen().ToSlice(),
+	)
+
+	_, err := MapErrOf(strconv.Atoi)(1.5)
+	assert.EqualError(t, err, "arg 0: type float64 is not convertible to string")
+
+	func() {
+		defer func() {
+			assert.Equal(t, "f must be a func of one arg that returns one value, optionally followed by an error", recover())
+		}()
+
+		MapOf(func(int) {})
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestConsumerOf(t *testing.T) {
+	var sum int
+	Of(1, 2, 3).AndThen().ForEach(ConsumerOf(func(i int) { sum += i }))
+	assert.Equal(t, 6, sum)
+
+	errBad := errors.New("bad")
+	func() {
+		defer func() {
+			assert.Equal(t, errBad, recover())
+		}()
+
+		ConsumerOf(func(int) error { return errBad })(1)
+		assert.Fail(t, "Must panic")
+	}()
+
+	ConsumerOf(func(int) error { return nil })(1)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "f must be a func of one arg that returns nothing or an error", recover())
+		}()
+
+		ConsumerOf(func(int) int { return 0 })
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestReduceOf(t *testing.T) {
+	sum := ReduceOf(func(acc, i int) int { return acc + i })
+	assert.Equal(t, 6, Of(1, 2, 3).AndThen().Reduce(0, sum))
+
+	concat := ReduceOf(func(acc string, i int) (string, error) { return acc + strconv.Itoa(i), nil })
+	assert.Equal(t, "123", Of(1, 2, 3).AndThen().Reduce("", concat))
+
+	for _, f := range []interface{}{nil, func(int) int { return 0 }, func(int, int) string { return "" }} {
+		func() {
+			defer func() {
+				assert.Equal(t, "f must be a func of two args that returns a value of the first arg type, optionally followed by an error", recover())
+			}()
+
+			ReduceOf(f)
+			assert.Fail(t, "Must panic")
+		}()
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// BloomFilter is a compact set membership structure, where a membership test may return a false positive, but never a
+// false negative. It is constructed by Finisher.ToBloomFilter, and MayContain can be passed to Filter, so that one
+// Stream can build a filter that later Streams use to quickly discard elements that cannot be members.
+// A BloomFilter is not safe for concurrent use if Add is called.
+type BloomFilter struct {
+	bits     []uint64
+	numBits  uint64
+	numHash  uint64
+	keyBytes func(element interface{}) []byte
+}
+
+// NewBloomFilter constructs an empty BloomFilter sized for expectedN elements with the given false positive rate, where
+// keyBytes returns the bytes that identify an element.
+// Panics if expectedN < 1, or fpRate is not > 0 and < 1.
+func NewBloomFilter(expectedN int, fpRate float64, keyBytes func(element interface{}) []byte) *BloomFilter {
+	if expectedN < 1 {
+		panic("expectedN must be > 0")
+	}
+
+	if (fpRate <= 0) || (fpRate >= 1) {
+		panic("fpRate must be > 0 and < 1")
+	}
+
+	// Optimal number of bits m = -n ln(p) / ln(2)^2, and number of hashes k = m/n ln(2)
+	var (
+		n       = float64(expectedN)
+		numBits = uint64(math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+		numHash = uint64(math.Max(1, math.Round(float64(numBits)/n*math.Ln2)))
+	)
+
+	return &BloomFilter{
+		bits:     make([]uint64, (numBits+63)/64),
+		numBits:  numBits,
+		numHash:  numHash,
+		keyBytes: keyBytes,
+	}
+}
+
+// indexes calls f with the index of each bit of an element, using double hashing of a 64 bit FNV-1a hash
+func (b *BloomFilter) indexes(element interface{}, f func(word uint64, mask uint64) bool) bool {
+	h := fnv.New64a()
+	h.Write(b.keyBytes(element))
+
+	var (
+		sum = h.Sum64()
+		h1  = sum & math.MaxUint32
+		h2  = sum >> 32
+	)
+
+	for i := uint64(0); i < b.numHash; i++ {
+		index := (h1 + i*h2) % b.numBits
+		if !f(index/64, 1<<(index%64)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Add adds an element
+func (b *BloomFilter) Add(element interface{}) {
+	b.indexes(element, func(word uint64, mask uint64) bool {
+		b.bits[word] |= mask
+		return true
+	})
+}
+
+// MayContain returns false if the element was definitely not added, or true if it probably was
+func (b *BloomFilter) MayContain(element interface{}) bool {
+	return b.indexes(element, func(word uint64, mask uint64) bool {
+		return b.bits[word]&mask != 0
+	})
+}
+
+// ToBloomFilter returns a BloomFilter of all elements, sized for expectedN elements with the given false positive rate,
+// where keyBytes returns the bytes that identify an element.
+// The false positive rate is higher than requested if there are more than expectedN elements.
+// Panics if expectedN < 1, or fpRate is not > 0 and < 1.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ToBloomFilter(expectedN int, fpRate float64, keyBytes func(element interface{}) []byte) *BloomFilter {
+	b := NewBloomFilter(expectedN, fpRate, keyBytes)
+
+	term := fin.terminal("ToBloomFilter")
+	defer term.done()
+
+	for it := term.Iter(); it.Next(); {
+		b.Add(it.Value())
+	}
+
+	return b
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilter(t *testing.T) {
+	var (
+		keyBytes = func(element interface{}) []byte { return []byte(strconv.Itoa(element.(int))) }
+		inc      = func(element interface{}) interface{} { return element.(int) + 1 }
+		b        = Iterate(-1, inc).Limit(1000).AndThen().ToBloomFilter(1000, 0.01, keyBytes)
+	)
+
+	// No false negatives
+	assert.True(t, Iterate(-1, inc).Limit(1000).AndThen().AllMatch(b.MayContain))
+
+	// False positive rate is close to the requested rate
+	falsePositives := Iterate(999, inc).Limit(10000).Filter(b.MayContain).AndThen().Count()
+	assert.True(t, falsePositives < 200, "%d false positives", falsePositives)
+
+	// Filter by membership
+	assert.Equal(t, []interface{}{5, 10}, Of(5, 10).Filter(b.MayContain).AndThen().ToSlice())
+
+	// Add
+	assert.False(t, b.MayContain(-5))
+	b.Add(-5)
+	assert.True(t, b.MayContain(-5))
+
+	func() {
+		defer func() {
+			assert.Equal(t, "expectedN must be > 0", recover())
+		}()
+
+		NewBloomFilter(0, 0.1, keyBytes)
+		assert.Fail(t, "Must panic")
+	}()
+
+	for _, fpRate := range []float64{0, 1} {
+		func() {
+			defer func() {
+				assert.Equal(t, "fpRate must be > 0 and < 1", recover())
+			}()
+
+			NewBloomFilter(1, fpRate, keyBytes)
+			assert.Fail(t, "Must panic")
+		}()
+	}
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"reflect"
+
+	"github.com/bantling/goiter"
+)
+
+// MergeChans constructs a Stream of the values received from several channels, until all of them are closed.
+// When more than one channel has a value ready, one is chosen at random, so that no channel can starve the others.
+// Values from each channel are in the order sent, but the order of values across channels is unspecified.
+// Nil channels are ignored. Iteration blocks while no channel has a value ready.
+func MergeChans(chs ...<-chan interface{}) Stream {
+	cases := make([]reflect.SelectCase, 0, len(chs))
+	for _, ch := range chs {
+		if ch != nil {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)})
+		}
+	}
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				for len(cases) > 0 {
+					chosen, val, ok := reflect.Select(cases)
+					if ok {
+						return val.Interface(), true
+					}
+
+					// Channel is closed, stop selecting it
+					cases = append(cases[:chosen], cases[chosen+1:]...)
+				}
+
+				return nil, false
+			},
+		),
+		true,
+	)
+}
+
+// FanOut distributes the elements across n channels with the given buffer size, so that each channel can be received
+// by a separate consumer. Elements are sent round robin, unless a partition function is provided, in which case each
+// element is sent to the channel at index partition(element) modulo n.
+// The elements are sent by a new goroutine, which closes the Stream and all channels after the last element.
+// Every channel must be received until closed, otherwise the goroutine blocks once the buffer of that channel is full.
+//
+// The returned func waits for the goroutine to finish, and returns an error if the source, a transform, or the
+// partition function panicked, else any error from closing the Stream. A panic closes all channels early, so consumers
+// are not left waiting for elements that will never be sent.
+// Panics if n < 1, or if the Finisher is infinite.
+func (fin Finisher) FanOut(
+	n int,
+	buffer int,
+	partition ...func(element interface{}) int,
+) ([]<-chan interface{}, func() error) {
+	if n < 1 {
+		panic("n must be > 0")
+	}
+
+	var (
+		term   = fin.terminal("FanOut")
+		it     = term.Iter()
+		chs    = make([]chan interface{}, n)
+		result = make([]<-chan interface{}, n)
+		done   = make(chan struct{})
+		err    error
+	)
+
+	for i := range chs {
+		chs[i] = make(chan interface{}, buffer)
+		result[i] = chs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, ch := range chs {
+				close(ch)
+			}
+
+			if closeErr := term.done(); err == nil {
+				err = closeErr
+			}
+
+			close(done)
+		}()
+		defer recoverError(&err)
+
+		for i := 0; it.Next(); i++ {
+			val := it.Value()
+
+			index := i % n
+			if len(partition) > 0 {
+				if index = partition[0](val) % n; index < 0 {
+					index += n
+				}
+			}
+
+			chs[index] <- val
+		}
+	}()
+
+	return result, func() error {
+		<-done
+		return err
+	}
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeChans(t *testing.T) {
+	// No channels
+	assert.Equal(t, []interface{}{}, MergeChans().AndThen().ToSlice())
+
+	// Closed and nil channels
+	closed := make(chan interface{})
+	close(closed)
+	assert.Equal(t, []interface{}{}, MergeChans(closed, nil).AndThen().ToSlice())
+
+	// Several producers
+	var (
+		chs  = make([]<-chan interface{}, 3)
+		want []interface{}
+	)
+	for i := range chs {
+		ch := make(chan interface{})
+		chs[i] = ch
+
+		go func(i int) {
+			defer close(ch)
+
+			for j := 0; j < 100; j++ {
+				ch <- i*1000 + j
+			}
+		}(i)
+
+		for j := 0; j < 100; j++ {
+			want = append(want, i*1000+j)
+		}
+	}
+
+	got := MergeChans(chs...).AndThen().ToSlice()
+	assert.ElementsMatch(t, want, got)
+
+	// Values from each channel are in order
+	last := map[int]int{0: -1, 1: -1, 2: -1}
+	for _, val := range got {
+		i, j := val.(int)/1000, val.(int)%1000
+		assert.Equal(t, last[i]+1, j)
+		last[i] = j
+	}
+}
+
+func TestStreamFanOut(t *testing.T) {
+	// Empty
+	chs, wait := Of().AndThen().FanOut(2, 0)
+	assert.Equal(t, 2, len(chs))
+	assert.Equal(t, []interface{}{}, MergeChans(chs...).AndThen().ToSlice())
+	assert.Nil(t, wait())
+
+	// Round robin
+	chs, wait = Of(1, 2, 3, 4, 5).AndThen().FanOut(2, 5)
+	assert.Equal(t, []interface{}{1, 3, 5}, receiveAll(chs[0]))
+	assert.Equal(t, []interface{}{2, 4}, receiveAll(chs[1]))
+	assert.Nil(t, wait())
+
+	// Partition, including negative results
+	chs, _ = Of(-3, -2, -1, 0, 1, 2, 3).AndThen().FanOut(3, 7, func(element interface{}) int { return element.(int) })
+	assert.Equal(t, []interface{}{-3, 0, 3}, receiveAll(chs[0]))
+	assert.Equal(t, []interface{}{-2, 1}, receiveAll(chs[1]))
+	assert.Equal(t, []interface{}{-1, 2}, receiveAll(chs[2]))
+
+	// Unbuffered channels received concurrently
+	chs, _ = Of(1, 2, 3, 4).AndThen().FanOut(2, 0)
+	assert.ElementsMatch(t, []interface{}{1, 2, 3, 4}, MergeChans(chs...).AndThen().ToSlice())
+
+	// A panic closes the channels, and is returned as an error
+	chs, wait = Of(1, 2, 3).
+		Map(func(element interface{}) interface{} {
+			if element.(int) == 2 {
+				panic(errors.New("bad element"))
+			}
+
+			return element
+		}).
+		AndThen().
+		FanOut(2, 0)
+	assert.Equal(t, []interface{}{1}, MergeChans(chs...).AndThen().ToSlice())
+	assert.EqualError(t, wait(), "bad element")
+
+	// Close error
+	chs, wait = Of(1).OnClose(func() error { return errors.New("close") }).AndThen().FanOut(1, 1)
+	assert.Equal(t, []interface{}{1}, receiveAll(chs[0]))
+	assert.EqualError(t, wait(), "close")
+
+	func() {
+		defer func() {
+			assert.Equal(t, "n must be > 0", recover())
+		}()
+
+		Of().AndThen().FanOut(0, 0)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+// receiveAll receives all values of a channel until it is closed
+func receiveAll(ch <-chan interface{}) []interface{} {
+	result := []interface{}{}
+	for val := range ch {
+		result = append(result, val)
+	}
+
+	return result
+}
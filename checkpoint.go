@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bantling/goiter"
+)
+
+// Checkpointer persists the number of source elements a long running terminal has consumed, so that a job that is
+// restarted after a crash can resume from where it left off with Stream.ResumeFrom, instead of reprocessing everything.
+// The state of stateful operations such as Distinct, DistinctBy, GroupByInto, and ReduceByKeyInto can be persisted
+// alongside the offset by passing them a StateStore that is not kept in process memory.
+type Checkpointer interface {
+	// Load returns the last saved offset, or 0 if no offset has been saved
+	Load() (offset uint64, err error)
+	// Save stores an offset, replacing any previously saved offset
+	Save(offset uint64) error
+}
+
+// FileCheckpointer is a Checkpointer that stores the offset as text in the file with the given path.
+// The offset is saved by writing a temporary file next to it and renaming it, so that a crash while saving leaves the
+// previous offset intact.
+type FileCheckpointer string
+
+// Load is Checkpointer.Load
+func (f FileCheckpointer) Load() (uint64, error) {
+	data, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// Save is Checkpointer.Save
+func (f FileCheckpointer) Save(offset uint64) error {
+	tmp := string(f) + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatUint(offset, 10)), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, string(f))
+}
+
+// ResumeFrom returns a new Stream that skips the first offset elements of the source, such as an offset loaded from a
+// Checkpointer. The elements are skipped before any transforms are applied, so ResumeFrom should be called on a Stream
+// before any sequential operations such as Limit or Sorted, which start a new source.
+func (s Stream) ResumeFrom(offset uint64) Stream {
+	source := s.source
+	newS := s
+	newS.source = func() *goiter.Iter {
+		var (
+			it      = source()
+			skipped bool
+		)
+
+		return goiter.NewIter(
+			func() (interface{}, bool) {
+				if !skipped {
+					skipped = true
+					for i := uint64(0); i < offset; i++ {
+						if !it.Next() {
+							return nil, false
+						}
+					}
+				}
+
+				if it.Next() {
+					return it.Value(), true
+				}
+
+				return nil, false
+			},
+		)
+	}
+
+	return newS
+}
+
+// WithCheckpoint returns a new Finisher that saves the number of source elements consumed to a Checkpointer after every
+// nth element, and when the source is exhausted.
+// The count starts from the offset loaded from the Checkpointer when the first element is requested, so the Stream must
+// be resumed from the same offset with Stream.ResumeFrom.
+//
+// An offset is saved when the next source element is requested, at which point every previous element has been fully
+// processed by the terminal, unless an operation buffers elements, such as Sorted or Buffer. In that case, the offset
+// only reflects how many source elements have been read.
+// Panics with an error during iteration if the offset cannot be loaded or saved.
+// Panics if every < 1.
+func (fin Finisher) WithCheckpoint(cp Checkpointer, every int) Finisher {
+	if every < 1 {
+		panic("every must be > 0")
+	}
+
+	var (
+		source = fin.source.source
+		newFin = fin
+	)
+
+	newFin.source.source = func() *goiter.Iter {
+		var (
+			it       = source()
+			loaded   bool
+			offset   uint64
+			consumed uint64
+			saved    uint64
+			save     = func() {
+				if consumed != saved {
+					if err := cp.Save(offset + consumed); err != nil {
+						panic(err)
+					}
+					saved = consumed
+				}
+			}
+		)
+
+		return goiter.NewIter(
+			func() (interface{}, bool) {
+				if !loaded {
+					loaded = true
+
+					var err error
+					if offset, err = cp.Load(); err != nil {
+						panic(err)
+					}
+				}
+
+				if (consumed > 0) && (consumed%uint64(every) == 0) {
+					save()
+				}
+
+				if !it.Next() {
+					save()
+					return nil, false
+				}
+
+				consumed++
+				return it.Value(), true
+			},
+		)
+	}
+
+	return newFin
+}
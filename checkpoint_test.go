@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingCheckpointer struct {
+	offset  uint64
+	saves   []uint64
+	loadErr error
+	saveErr error
+}
+
+func (r *recordingCheckpointer) Load() (uint64, error) {
+	return r.offset, r.loadErr
+}
+
+func (r *recordingCheckpointer) Save(offset uint64) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+
+	r.offset = offset
+	r.saves = append(r.saves, offset)
+	return nil
+}
+
+func TestFileCheckpointer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostream")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cp := FileCheckpointer(filepath.Join(dir, "offset"))
+	offset, err := cp.Load()
+	assert.Equal(t, uint64(0), offset)
+	assert.Nil(t, err)
+
+	assert.Nil(t, cp.Save(12))
+	offset, err = cp.Load()
+	assert.Equal(t, uint64(12), offset)
+	assert.Nil(t, err)
+
+	assert.Nil(t, cp.Save(345))
+	offset, err = cp.Load()
+	assert.Equal(t, uint64(345), offset)
+	assert.Nil(t, err)
+
+	_, err = os.Stat(string(cp) + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Nil(t, ioutil.WriteFile(string(cp), []byte("x"), 0644))
+	_, err = cp.Load()
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, FileCheckpointer(filepath.Join(dir, "missing", "offset")).Save(1))
+}
+
+func TestStreamResumeFrom(t *testing.T) {
+	double := func(element interface{}) interface{} { return element.(int) * 2 }
+
+	assert.Equal(t, []int{1, 2, 3}, Of(1, 2, 3).ResumeFrom(0).AndThen().ToSliceOf(0))
+	assert.Equal(t, []int{6, 8}, Of(1, 2, 3, 4).ResumeFrom(2).Map(double).AndThen().ToSliceOf(0))
+	assert.Equal(t, []int{6, 8}, Of(1, 2, 3, 4).Map(double).ResumeFrom(2).AndThen().ToSliceOf(0))
+	assert.Equal(t, []interface{}{}, Of(1, 2).ResumeFrom(2).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{}, Of(1, 2).ResumeFrom(5).AndThen().ToSlice())
+}
+
+func TestFinisherWithCheckpoint(t *testing.T) {
+	var (
+		odd    = func(element interface{}) bool { return element.(int)%2 == 1 }
+		values = []interface{}{1, 2, 3, 4, 5, 6, 7}
+	)
+
+	// Saves after every 3rd source element, and at the end
+	cp := &recordingCheckpointer{}
+	assert.Equal(t, 7, Of(values...).AndThen().WithCheckpoint(cp, 3).Count())
+	assert.Equal(t, []uint64{3, 6, 7}, cp.saves)
+
+	// Counts source elements, not elements that reach the checkpoint
+	cp = &recordingCheckpointer{}
+	assert.Equal(t, 4, Of(values...).Filter(odd).AndThen().WithCheckpoint(cp, 2).Count())
+	assert.Equal(t, []uint64{2, 4, 6, 7}, cp.saves)
+
+	// The end is not saved again if it was just saved
+	cp = &recordingCheckpointer{}
+	assert.Equal(t, 6, Of(1, 2, 3, 4, 5, 6).AndThen().WithCheckpoint(cp, 3).Count())
+	assert.Equal(t, []uint64{3, 6}, cp.saves)
+
+	// An offset is not saved until the element before it has been processed
+	var (
+		processed []int
+		saved     []uint64
+	)
+	cp = &recordingCheckpointer{}
+	Of(values...).AndThen().WithCheckpoint(cp, 2).Limit(5).ForEach(func(element interface{}) {
+		processed = append(processed, element.(int))
+		if len(cp.saves) > len(saved) {
+			saved = append(saved, cp.saves[len(cp.saves)-1])
+			assert.True(t, saved[len(saved)-1] < uint64(element.(int)))
+		}
+	})
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, processed)
+	assert.Equal(t, []uint64{2, 4}, cp.saves)
+
+	// Resume after a crash
+	cp = &recordingCheckpointer{}
+	func() {
+		defer func() {
+			assert.Equal(t, "crash", recover())
+		}()
+
+		Of(values...).AndThen().WithCheckpoint(cp, 2).ForEach(func(element interface{}) {
+			if element.(int) == 6 {
+				panic("crash")
+			}
+		})
+		assert.Fail(t, "Must panic")
+	}()
+	assert.Equal(t, uint64(4), cp.offset)
+
+	offset, _ := cp.Load()
+	assert.Equal(t, []int{5, 6, 7}, Of(values...).ResumeFrom(offset).AndThen().WithCheckpoint(cp, 2).ToSliceOf(0))
+	assert.Equal(t, uint64(7), cp.offset)
+
+	// Load error
+	loadErr := errors.New("load")
+	func() {
+		defer func() {
+			assert.Equal(t, loadErr, recover())
+		}()
+
+		Of(1).AndThen().WithCheckpoint(&recordingCheckpointer{loadErr: loadErr}, 1).Count()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Save error
+	saveErr := errors.New("save")
+	func() {
+		defer func() {
+			assert.Equal(t, saveErr, recover())
+		}()
+
+		Of(1, 2).AndThen().WithCheckpoint(&recordingCheckpointer{saveErr: saveErr}, 1).Count()
+		assert.Fail(t, "Must panic")
+	}()
+
+	func() {
+		defer func() {
+			assert.Equal(t, "every must be > 0", recover())
+		}()
+
+		Of().AndThen().WithCheckpoint(cp, 0)
+		assert.Fail(t, "Must panic")
+	}()
+}
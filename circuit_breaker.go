@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the error given to the fallback of Stream.MapWithCircuitBreaker for elements that are not mapped
+// because the circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState uint
+
+const (
+	// CircuitClosed is the initial state, in which every element is mapped
+	CircuitClosed CircuitState = iota
+	// CircuitOpen is the state after too many consecutive failures, in which no elements are mapped
+	CircuitOpen
+	// CircuitHalfOpen is the state after the open duration has elapsed, in which one element is mapped as a probe.
+	// If the probe succeeds the circuit closes, otherwise it opens again.
+	CircuitHalfOpen
+)
+
+// CircuitBreaker tracks failures of a fallible mapping function, such as a call to an external service, so that
+// Stream.MapWithCircuitBreaker stops calling it after threshold consecutive failures, and only tries again after the
+// open duration has elapsed.
+// A CircuitBreaker is safe for concurrent use, so one can be shared by parallel goroutines or multiple Streams that call
+// the same dependency.
+type CircuitBreaker struct {
+	threshold    int
+	openDuration time.Duration
+	now          func() time.Time
+	mutex        sync.Mutex
+	state        CircuitState
+	failures     int
+	openedAt     time.Time
+	probing      bool
+}
+
+// NewCircuitBreaker constructs a closed CircuitBreaker that opens after threshold consecutive failures, and stays open
+// for openDuration before allowing a probe.
+// Panics if threshold < 1 or openDuration <= 0.
+func NewCircuitBreaker(threshold int, openDuration time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		panic("threshold must be > 0")
+	}
+
+	if openDuration <= 0 {
+		panic("openDuration must be > 0")
+	}
+
+	return &CircuitBreaker{
+		threshold:    threshold,
+		openDuration: openDuration,
+		now:          time.Now,
+	}
+}
+
+// State returns the current state. An open circuit whose open duration has elapsed is reported as half open.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if (cb.state == CircuitOpen) && (cb.now().Sub(cb.openedAt) >= cb.openDuration) {
+		return CircuitHalfOpen
+	}
+
+	return cb.state
+}
+
+// allow returns true if an element may be mapped, in which case the caller must call record with the outcome
+func (cb *CircuitBreaker) allow() bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	switch cb.state {
+	case CircuitClosed:
+		return true
+
+	case CircuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.openDuration {
+			return false
+		}
+
+		cb.state = CircuitHalfOpen
+	}
+
+	// Half open, only one probe at a time
+	if cb.probing {
+		return false
+	}
+
+	cb.probing = true
+	return true
+}
+
+// record records the outcome of mapping an element that was allowed
+func (cb *CircuitBreaker) record(failed bool) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.state == CircuitHalfOpen {
+		cb.probing = false
+	}
+
+	if !failed {
+		cb.state, cb.failures = CircuitClosed, 0
+		return
+	}
+
+	cb.failures++
+	if (cb.state == CircuitHalfOpen) || (cb.failures >= cb.threshold) {
+		cb.state, cb.openedAt = CircuitOpen, cb.now()
+	}
+}
+
+// MapWithCircuitBreaker returns a new stream that maps each element with f, unless the circuit breaker is open.
+// If f returns an error, or the circuit is open and f is not called, the element is mapped with fallback, which is
+// given the error from f or ErrCircuitOpen. If fallback is nil, the stream panics with the error.
+// A panic in f counts as a failure, and is rethrown.
+func (s Stream) MapWithCircuitBreaker(
+	cb *CircuitBreaker,
+	f func(element interface{}) (interface{}, error),
+	fallback func(element interface{}, err error) interface{},
+) Stream {
+	return s.Map(
+		func(element interface{}) interface{} {
+			var (
+				value interface{}
+				err   = ErrCircuitOpen
+			)
+
+			if cb.allow() {
+				func() {
+					failed := true
+					defer func() { cb.record(failed) }()
+
+					value, err = f(element)
+					failed = err != nil
+				}()
+			}
+
+			if err == nil {
+				return value
+			}
+
+			if fallback == nil {
+				panic(err)
+			}
+
+			return fallback(element, err)
+		},
+	)
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		now     = time.Now()
+		cb      = NewCircuitBreaker(2, time.Minute)
+		calls   []interface{}
+		failErr = errors.New("unavailable")
+		healthy bool
+		f       = func(element interface{}) (interface{}, error) {
+			calls = append(calls, element)
+			if !healthy {
+				return nil, failErr
+			}
+			return element.(int) * 2, nil
+		}
+		errs     []error
+		fallback = func(element interface{}, err error) interface{} {
+			errs = append(errs, err)
+			return -element.(int)
+		}
+	)
+	cb.now = func() time.Time { return now }
+
+	// Two failures open the circuit, so the remaining elements are not mapped
+	assert.Equal(t, CircuitClosed, cb.State())
+	assert.Equal(t, []interface{}{-1, -2, -3, -4}, Of(1, 2, 3, 4).MapWithCircuitBreaker(cb, f, fallback).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, calls)
+	assert.Equal(t, []error{failErr, failErr, ErrCircuitOpen, ErrCircuitOpen}, errs)
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	// After the open duration, a failed probe opens the circuit again
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	calls, errs = nil, nil
+	assert.Equal(t, []interface{}{-5, -6}, Of(5, 6).MapWithCircuitBreaker(cb, f, fallback).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{5}, calls)
+	assert.Equal(t, []error{failErr, ErrCircuitOpen}, errs)
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	// A successful probe closes the circuit
+	now = now.Add(time.Minute)
+	healthy = true
+	calls = nil
+	assert.Equal(t, []interface{}{14, 16}, Of(7, 8).MapWithCircuitBreaker(cb, f, fallback).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{7, 8}, calls)
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	// A success resets the consecutive failure count
+	healthy = false
+	Of(1).MapWithCircuitBreaker(cb, f, fallback).AndThen().ToSlice()
+	healthy = true
+	Of(1).MapWithCircuitBreaker(cb, f, fallback).AndThen().ToSlice()
+	healthy = false
+	Of(1).MapWithCircuitBreaker(cb, f, fallback).AndThen().ToSlice()
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	// No fallback
+	func() {
+		defer func() {
+			assert.Equal(t, failErr, recover())
+		}()
+
+		Of(1).MapWithCircuitBreaker(cb, f, nil).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	// A panic counts as a failure of the probe
+	now = now.Add(time.Minute)
+	func() {
+		defer func() {
+			assert.Equal(t, "bad", recover())
+		}()
+
+		Of(1).MapWithCircuitBreaker(cb, func(interface{}) (interface{}, error) { panic("bad") }, fallback).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	func() {
+		defer func() {
+			assert.Equal(t, "threshold must be > 0", recover())
+		}()
+
+		NewCircuitBreaker(0, time.Second)
+		assert.Fail(t, "Must panic")
+	}()
+
+	func() {
+		defer func() {
+			assert.Equal(t, "openDuration must be > 0", recover())
+		}()
+
+		NewCircuitBreaker(1, 0)
+		assert.Fail(t, "Must panic")
+	}()
+}
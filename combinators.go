@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+// And returns a predicate that is true if all the given predicates are true, evaluating them in order and stopping at
+// the first false result. With no predicates, the result is always true.
+func And(preds ...func(element interface{}) bool) func(element interface{}) bool {
+	return func(element interface{}) bool {
+		for _, pred := range preds {
+			if !pred(element) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Or returns a predicate that is true if any of the given predicates are true, evaluating them in order and stopping
+// at the first true result. With no predicates, the result is always false.
+func Or(preds ...func(element interface{}) bool) func(element interface{}) bool {
+	return func(element interface{}) bool {
+		for _, pred := range preds {
+			if pred(element) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Not returns a predicate that is the negation of the given predicate
+func Not(pred func(element interface{}) bool) func(element interface{}) bool {
+	return func(element interface{}) bool {
+		return !pred(element)
+	}
+}
+
+// ComposeMaps returns a mapping function that applies the given mapping functions in order, passing the result of
+// each to the next. With no mapping functions, the result is the element unchanged.
+func ComposeMaps(maps ...func(element interface{}) interface{}) func(element interface{}) interface{} {
+	return func(element interface{}) interface{} {
+		for _, f := range maps {
+			element = f(element)
+		}
+
+		return element
+	}
+}
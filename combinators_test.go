@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredicateCombinators(t *testing.T) {
+	var (
+		calls    int
+		positive = func(element interface{}) bool { calls++; return element.(int) > 0 }
+		even     = func(element interface{}) bool { calls++; return element.(int)%2 == 0 }
+	)
+
+	assert.True(t, And()(1))
+	assert.True(t, And(positive, even)(2))
+	assert.False(t, And(positive, even)(1))
+
+	// Short circuit
+	calls = 0
+	assert.False(t, And(positive, even)(-1))
+	assert.Equal(t, 1, calls)
+
+	assert.False(t, Or()(1))
+	assert.True(t, Or(positive, even)(-2))
+	assert.False(t, Or(positive, even)(-1))
+
+	calls = 0
+	assert.True(t, Or(positive, even)(1))
+	assert.Equal(t, 1, calls)
+
+	assert.False(t, Not(positive)(1))
+	assert.True(t, Not(positive)(-1))
+
+	assert.Equal(t, []interface{}{-3, 1}, Of(-3, -2, 1, 2).Filter(Not(even)).AndThen().ToSlice())
+}
+
+func TestComposeMaps(t *testing.T) {
+	var (
+		inc    = func(element interface{}) interface{} { return element.(int) + 1 }
+		double = func(element interface{}) interface{} { return element.(int) * 2 }
+	)
+
+	assert.Equal(t, 1, ComposeMaps()(1))
+	assert.Equal(t, 4, ComposeMaps(inc, double)(1))
+	assert.Equal(t, 3, ComposeMaps(double, inc)(1))
+	assert.Equal(t, []interface{}{4, 6}, Of(1, 2).Map(ComposeMaps(inc, double)).AndThen().ToSlice())
+}
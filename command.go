@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"bufio"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/bantling/goiter"
+)
+
+// OfCommandLines constructs a stream of the lines written to stdout by a command, without trailing newlines.
+// The command is started when the first line is read, and must not have been started already, or have Stdout set.
+// The exit status of the command is reported when the stream is closed, which every terminal does when it completes:
+// Stream.Close returns any error from exec.Cmd.Wait, such as an *exec.ExitError for a non-zero exit status.
+// If the stream is closed before all lines are read, the command is killed, and no error is reported for it.
+// The stream panics with an error during iteration if the command cannot be started, or stdout cannot be read.
+func OfCommandLines(cmd *exec.Cmd) Stream {
+	var (
+		br        *bufio.Reader
+		started   bool
+		exhausted bool
+	)
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				if !started {
+					stdout, err := cmd.StdoutPipe()
+					if err != nil {
+						panic(err)
+					}
+
+					if err = cmd.Start(); err != nil {
+						panic(err)
+					}
+
+					// Only a started command has a process to kill or wait for when the stream is closed
+					started = true
+					br = bufio.NewReader(stdout)
+				}
+
+				if exhausted {
+					return nil, false
+				}
+
+				line, err := br.ReadString('\n')
+				if err == io.EOF {
+					exhausted = true
+					if line == "" {
+						return nil, false
+					}
+				} else if err != nil {
+					panic(err)
+				}
+
+				return strings.TrimSuffix(line, "\n"), true
+			},
+		),
+		true,
+	).OnClose(
+		func() error {
+			if !started {
+				return nil
+			}
+
+			if !exhausted {
+				// The remaining output is not wanted, so stop the command rather than wait for it.
+				// The errors are ignored, since the command may have exited already, and is expected to fail once killed.
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+				return nil
+			}
+
+			return cmd.Wait()
+		},
+	)
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfCommandLines(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	// Empty output
+	s := OfCommandLines(exec.Command("sh", "-c", "true"))
+	assert.Equal(t, []interface{}{}, s.AndThen().ToSlice())
+	assert.Nil(t, s.Close())
+
+	// Lines, with and without a trailing newline
+	s = OfCommandLines(exec.Command("sh", "-c", "echo a; echo; printf b"))
+	assert.Equal(t, []interface{}{"a", "", "b"}, s.AndThen().ToSlice())
+	assert.Nil(t, s.Close())
+
+	// Exit status
+	s = OfCommandLines(exec.Command("sh", "-c", "echo a; exit 3"))
+	assert.Equal(t, []interface{}{"a"}, s.AndThen().ToSlice())
+	err := s.Close()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "exit status 3")
+
+	// Closed early
+	s = OfCommandLines(exec.Command("sh", "-c", "yes"))
+	assert.Equal(t, []interface{}{"y", "y"}, s.AndThen().Limit(2).ToSlice())
+	assert.Nil(t, s.Close())
+
+	// Never started
+	assert.Nil(t, OfCommandLines(exec.Command("sh")).Close())
+
+	// Cannot be started, the start error is not hidden by closing the stream
+	func() {
+		defer func() {
+			err, isErr := recover().(error)
+			if assert.True(t, isErr) {
+				assert.Contains(t, err.Error(), "/nonexistent/command")
+			}
+		}()
+
+		OfCommandLines(exec.Command("/nonexistent/command")).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Stdout cannot be piped
+	func() {
+		cmd := exec.Command("sh", "-c", "true")
+		cmd.Stdout = &strings.Builder{}
+
+		defer func() {
+			err, isErr := recover().(error)
+			if assert.True(t, isErr) {
+				assert.Equal(t, "exec: Stdout already set", err.Error())
+			}
+		}()
+
+		OfCommandLines(cmd).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"reflect"
+)
+
+// Comparator is a less function that can be built up from simpler comparators, for multi-key sorting.
+// A Comparator can be passed to Sorted, ReverseSorted, Max, and Min, as can any less function of the same signature,
+// such as those provided by gofuncs.
+//
+// Example sorting by last name, then first name in descending order:
+//
+//	Comparing(lastName, gofuncs.StringSortFunc).ThenComparing(Comparing(firstName, gofuncs.StringSortFunc).Reversed())
+type Comparator func(element1, element2 interface{}) bool
+
+// Comparing returns a Comparator that compares the keys extracted from each element with the given less function
+func Comparing(key func(element interface{}) interface{}, less func(key1, key2 interface{}) bool) Comparator {
+	return func(element1, element2 interface{}) bool {
+		return less(key(element1), key(element2))
+	}
+}
+
+// ThenComparing returns a Comparator that compares elements with this Comparator, and then with the next Comparator
+// if neither element is less than the other
+func (c Comparator) ThenComparing(next func(element1, element2 interface{}) bool) Comparator {
+	return func(element1, element2 interface{}) bool {
+		if c(element1, element2) {
+			return true
+		}
+
+		if c(element2, element1) {
+			return false
+		}
+
+		return next(element1, element2)
+	}
+}
+
+// Reversed returns a Comparator that orders elements in the opposite order of this Comparator
+func (c Comparator) Reversed() Comparator {
+	return func(element1, element2 interface{}) bool {
+		return c(element2, element1)
+	}
+}
+
+// NilsFirst returns a Comparator that orders nil elements before all others, and compares non-nil elements with this
+// Comparator, which is never called with a nil element.
+// An element is nil if it is a nil interface, or a nil chan, func, interface, map, pointer, or slice.
+func (c Comparator) NilsFirst() Comparator {
+	return c.nils(true)
+}
+
+// NilsLast returns a Comparator that orders nil elements after all others, and compares non-nil elements with this
+// Comparator, which is never called with a nil element.
+// An element is nil if it is a nil interface, or a nil chan, func, interface, map, pointer, or slice.
+func (c Comparator) NilsLast() Comparator {
+	return c.nils(false)
+}
+
+// nils is the implementation of NilsFirst and NilsLast
+func (c Comparator) nils(first bool) Comparator {
+	return func(element1, element2 interface{}) bool {
+		nil1, nil2 := isNil(element1), isNil(element2)
+
+		switch {
+		case nil1 && nil2:
+			return false
+		case nil1:
+			return first
+		case nil2:
+			return !first
+		}
+
+		return c(element1, element2)
+	}
+}
+
+// isNil is true if the element is a nil interface, or a nil value of a kind that can be nil
+func isNil(element interface{}) bool {
+	if element == nil {
+		return true
+	}
+
+	switch val := reflect.ValueOf(element); val.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return val.IsNil()
+	}
+
+	return false
+}
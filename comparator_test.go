@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"testing"
+
+	"github.com/bantling/gofuncs"
+	"github.com/stretchr/testify/assert"
+)
+
+type comparatorPerson struct {
+	first, last string
+}
+
+func TestComparator(t *testing.T) {
+	var (
+		first = func(element interface{}) interface{} { return element.(comparatorPerson).first }
+		last  = func(element interface{}) interface{} { return element.(comparatorPerson).last }
+		byKey = Comparing(last, gofuncs.StringSortFunc).
+			ThenComparing(Comparing(first, gofuncs.StringSortFunc).Reversed())
+		people = []interface{}{
+			comparatorPerson{"Ann", "Smith"},
+			comparatorPerson{"Bob", "Jones"},
+			comparatorPerson{"Cal", "Smith"},
+			comparatorPerson{"Ann", "Jones"},
+		}
+	)
+
+	assert.Equal(
+		t,
+		[]interface{}{
+			comparatorPerson{"Bob", "Jones"},
+			comparatorPerson{"Ann", "Jones"},
+			comparatorPerson{"Cal", "Smith"},
+			comparatorPerson{"Ann", "Smith"},
+		},
+		Of(people...).AndThen().Sorted(byKey).ToSlice(),
+	)
+
+	assert.Equal(t, comparatorPerson{"Ann", "Smith"}, Of(people...).AndThen().Max(byKey).MustGet())
+	assert.Equal(t, comparatorPerson{"Bob", "Jones"}, Of(people...).AndThen().Min(byKey).MustGet())
+
+	// Reversed
+	assert.Equal(
+		t,
+		[]interface{}{3, 2, 1},
+		Of(2, 3, 1).AndThen().Sorted(Comparator(gofuncs.IntSortFunc).Reversed()).ToSlice(),
+	)
+
+	// Nils
+	var (
+		one   = 1
+		two   = 2
+		deref = Comparing(func(element interface{}) interface{} { return *element.(*int) }, gofuncs.IntSortFunc)
+	)
+
+	sorted := Of(&two, (*int)(nil), &one, nil).AndThen().Sorted(deref.NilsFirst()).ToSlice()
+	assert.True(t, isNil(sorted[0]))
+	assert.True(t, isNil(sorted[1]))
+	assert.Equal(t, []interface{}{&one, &two}, sorted[2:])
+
+	sorted = Of(&two, (*int)(nil), &one, nil).AndThen().Sorted(deref.NilsLast()).ToSlice()
+	assert.Equal(t, []interface{}{&one, &two}, sorted[:2])
+	assert.True(t, isNil(sorted[2]))
+	assert.True(t, isNil(sorted[3]))
+}
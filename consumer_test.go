@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// The tests of consumer are in an external package, so that the calls under test come from outside of gostream, the
+// same as in any other program
+package gostream_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/bantling/gostream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamConsumed(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	assertConsumedBy := func(name string, line int) {
+		assert.Equal(t, fmt.Sprintf(gostream.ErrStreamConsumed, name, fmt.Sprintf("%s:%d", file, line)), recover())
+	}
+
+	// Consumed by a terminal
+	s := gostream.Of(1, 2)
+	_, _, line, _ := runtime.Caller(0)
+	assert.Equal(t, 2, s.AndThen().Count())
+	func() {
+		defer assertConsumedBy("Finisher.Count", line+1)
+
+		s.AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Consumed by an iterator
+	s = gostream.Of(1)
+	it := s.Iter()
+	assert.True(t, it.Next())
+	_, _, line, _ = runtime.Caller(0)
+	assert.False(t, it.Next())
+	func() {
+		defer assertConsumedBy("Stream.Iter", line+1)
+
+		s.Iter().Next()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// A stream that is not exhausted can be read again
+	s = gostream.Of(1, 2)
+	assert.Equal(t, 1, s.AndThen().FindFirst().MustGet())
+	assert.Equal(t, []interface{}{2}, s.AndThen().ToSlice())
+}
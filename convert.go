@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/bantling/gooptional"
+)
+
+// float64Type is the reflect.Type of float64, which TryAverage and TrySum convert elements to
+var float64Type = reflect.TypeOf(float64(0))
+
+// convertTo converts a value to the given type, where nil converts to the zero value of any type that can be nil.
+// Returns an error if the value is not convertible.
+func convertTo(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	val := reflect.ValueOf(value)
+	if !val.IsValid() {
+		switch typ.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			return reflect.Zero(typ), nil
+		}
+
+		return reflect.Value{}, fmt.Errorf("nil is not convertible to %s", typ)
+	}
+
+	if !val.Type().ConvertibleTo(typ) {
+		return reflect.Value{}, fmt.Errorf("type %s is not convertible to %s", val.Type(), typ)
+	}
+
+	return val.Convert(typ), nil
+}
+
+// TryIterateFunc is like IterateFunc, except that it returns an error instead of panicking if f is not a func that
+// accepts and returns one type that is exactly the same.
+func TryIterateFunc(f interface{}) (func(interface{}) interface{}, error) {
+	val := reflect.ValueOf(f)
+	if val.Kind() != reflect.Func {
+		return nil, errors.New("f must be a function")
+	}
+
+	typ := val.Type()
+
+	if (typ.NumIn() != 1) || (typ.NumOut() != 1) || (typ.In(0) != typ.Out(0)) {
+		return nil, errors.New("f must accept and return a single value of the exact same type")
+	}
+
+	return func(arg interface{}) interface{} {
+		return val.Call([]reflect.Value{reflect.ValueOf(arg)})[0].Interface()
+	}, nil
+}
+
+// TryToSliceOf is like ToSliceOf, except that it returns an error instead of panicking if an element is not convertible
+// to the type of elementVal, in which case no further elements are read.
+// Also returns any error closing the Stream.
+// Panics if the Finisher is infinite.
+func (fin Finisher) TryToSliceOf(elementVal interface{}) (slice interface{}, err error) {
+	term := fin.terminal("TryToSliceOf")
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var (
+		elementTyp = reflect.TypeOf(elementVal)
+		array      = reflect.MakeSlice(reflect.SliceOf(elementTyp), 0, 0)
+		n          int
+	)
+
+	for it := term.Iter(); it.Next(); n++ {
+		val, convErr := convertTo(it.Value(), elementTyp)
+		if convErr != nil {
+			err = fmt.Errorf("element %d: %w", n, convErr)
+			return slice, err
+		}
+
+		array = reflect.Append(array, val)
+	}
+
+	slice = array.Interface()
+	return slice, err
+}
+
+// TryToMapOf is like ToMapOf, except that it returns an error instead of panicking if a key or value is not
+// convertible to the type of aKey or aValue, in which case no further elements are read.
+// Also returns any error closing the Stream.
+// Panics if the Finisher is infinite.
+func (fin Finisher) TryToMapOf(
+	f func(interface{}) (key interface{}, value interface{}),
+	aKey, aValue interface{},
+) (result interface{}, err error) {
+	term := fin.terminal("TryToMapOf")
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var (
+		ktyp = reflect.TypeOf(aKey)
+		vtyp = reflect.TypeOf(aValue)
+		m    = reflect.MakeMap(reflect.MapOf(ktyp, vtyp))
+		n    int
+	)
+
+	for it := term.Iter(); it.Next(); n++ {
+		k, v := f(it.Value())
+
+		kval, convErr := convertTo(k, ktyp)
+		if convErr != nil {
+			err = fmt.Errorf("key of element %d: %w", n, convErr)
+			return result, err
+		}
+
+		vval, convErr := convertTo(v, vtyp)
+		if convErr != nil {
+			err = fmt.Errorf("value of element %d: %w", n, convErr)
+			return result, err
+		}
+
+		m.SetMapIndex(kval, vval)
+	}
+
+	result = m.Interface()
+	return result, err
+}
+
+// trySum is the common implementation of TryAverage and TrySum, which returns the sum and count of the elements
+func (fin Finisher) trySum(name string) (sum float64, count int, err error) {
+	term := fin.terminal(name)
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	for it := term.Iter(); it.Next(); count++ {
+		val, convErr := convertTo(it.Value(), float64Type)
+		if convErr != nil {
+			err = fmt.Errorf("element %d: %w", count, convErr)
+			return sum, count, err
+		}
+
+		sum += val.Float()
+	}
+
+	return sum, count, err
+}
+
+// TryAverage is like Average, except that it returns an error instead of panicking if an element is not convertible
+// to a float64, in which case no further elements are read.
+// Also returns any error closing the Stream.
+// Panics if the Finisher is infinite.
+func (fin Finisher) TryAverage() (gooptional.Optional, error) {
+	sum, count, err := fin.trySum("TryAverage")
+	if (err != nil) || (count == 0) {
+		return gooptional.Of(), err
+	}
+
+	return gooptional.Of(sum / float64(count)), nil
+}
+
+// TrySum is like Sum, except that it returns an error instead of panicking if an element is not convertible to a
+// float64, in which case no further elements are read.
+// Also returns any error closing the Stream.
+// Panics if the Finisher is infinite.
+func (fin Finisher) TrySum() (gooptional.Optional, error) {
+	sum, count, err := fin.trySum("TrySum")
+	if (err != nil) || (count == 0) {
+		return gooptional.Of(), err
+	}
+
+	return gooptional.Of(sum), nil
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryIterateFunc(t *testing.T) {
+	fn, err := TryIterateFunc(func(i int) int { return i + 1 })
+	assert.Nil(t, err)
+	assert.Equal(t, 2, fn(1))
+
+	for _, f := range []interface{}{nil, 1} {
+		fn, err = TryIterateFunc(f)
+		assert.Nil(t, fn)
+		assert.EqualError(t, err, "f must be a function")
+	}
+
+	for _, f := range []interface{}{func() int { return 0 }, func(int) string { return "" }, func(int) (int, int) { return 0, 0 }} {
+		fn, err = TryIterateFunc(f)
+		assert.Nil(t, fn)
+		assert.EqualError(t, err, "f must accept and return a single value of the exact same type")
+	}
+
+	func() {
+		defer func() {
+			assert.Equal(t, "f must be a function", recover())
+		}()
+
+		IterateFunc(1)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestTryToSliceOf(t *testing.T) {
+	slice, err := Of().AndThen().TryToSliceOf(0)
+	assert.Equal(t, []int{}, slice)
+	assert.Nil(t, err)
+
+	slice, err = Of(1, int8(2)).AndThen().TryToSliceOf(0)
+	assert.Equal(t, []int{1, 2}, slice)
+	assert.Nil(t, err)
+
+	slice, err = Of(nil).AndThen().TryToSliceOf([]int{})
+	assert.Equal(t, [][]int{nil}, slice)
+	assert.Nil(t, err)
+
+	slice, err = Of(1, "a").AndThen().TryToSliceOf(0)
+	assert.Nil(t, slice)
+	assert.EqualError(t, err, "element 1: type string is not convertible to int")
+
+	slice, err = Of(nil).AndThen().TryToSliceOf(0)
+	assert.Nil(t, slice)
+	assert.EqualError(t, err, "element 0: nil is not convertible to int")
+
+	_, err = Of().OnClose(func() error { return errors.New("close") }).AndThen().TryToSliceOf(0)
+	assert.EqualError(t, err, "close")
+}
+
+func TestTryToMapOf(t *testing.T) {
+	fn := func(element interface{}) (interface{}, interface{}) {
+		return element, strconv.Itoa(element.(int))
+	}
+
+	m, err := Of(1, 2).AndThen().TryToMapOf(fn, 0, "")
+	assert.Equal(t, map[int]string{1: "1", 2: "2"}, m)
+	assert.Nil(t, err)
+
+	m, err = Of(1, 2).AndThen().TryToMapOf(fn, true, "")
+	assert.Nil(t, m)
+	assert.EqualError(t, err, "key of element 0: type int is not convertible to bool")
+
+	m, err = Of(1, 2).AndThen().TryToMapOf(fn, 0, 0)
+	assert.Nil(t, m)
+	assert.EqualError(t, err, "value of element 0: type string is not convertible to int")
+}
+
+func TestTryAverageSum(t *testing.T) {
+	avg, err := Of().AndThen().TryAverage()
+	assert.True(t, avg.IsEmpty())
+	assert.Nil(t, err)
+
+	sum, err := Of().AndThen().TrySum()
+	assert.True(t, sum.IsEmpty())
+	assert.Nil(t, err)
+
+	avg, err = Of(1, uint8(2), 4.5).AndThen().TryAverage()
+	assert.Equal(t, 2.5, avg.MustGet())
+	assert.Nil(t, err)
+
+	sum, err = Of(1, uint8(2), 4.5).AndThen().TrySum()
+	assert.Equal(t, 7.5, sum.MustGet())
+	assert.Nil(t, err)
+
+	avg, err = Of(1, "a").AndThen().TryAverage()
+	assert.True(t, avg.IsEmpty())
+	assert.EqualError(t, err, "element 1: type string is not convertible to float64")
+
+	sum, err = Of(nil).AndThen().TrySum()
+	assert.True(t, sum.IsEmpty())
+	assert.EqualError(t, err, "element 0: nil is not convertible to float64")
+
+	_, err = Of(1).OnClose(func() error { return errors.New("close") }).AndThen().TrySum()
+	assert.EqualError(t, err, "close")
+}
@@ -0,0 +1,298 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/bantling/goiter"
+)
+
+const (
+	// ErrCSVProtoNotStruct is thrown when OfCSVStructs is given a proto that is not a struct or pointer to struct
+	ErrCSVProtoNotStruct = "proto must be a struct or pointer to struct"
+)
+
+// csvField is a struct field mapped to a CSV column
+type csvField struct {
+	name  string
+	index int
+}
+
+// csvFields returns the fields of a struct type that map to CSV columns, in field order.
+// A field maps to the column named by its csv tag, or by its field name if it has no tag.
+// Unexported fields and fields tagged with "-" are ignored.
+// Panics if a mapped field is not a string, bool, int, uint, or float kind.
+func csvFields(typ reflect.Type) []csvField {
+	var fields []csvField
+
+	for i, n := 0, typ.NumField(); i < n; i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Tag.Get("csv")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		switch field.Type.Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		default:
+			panic(fmt.Sprintf("field %s of type %s cannot be mapped to a CSV column", field.Name, field.Type))
+		}
+
+		fields = append(fields, csvField{name: name, index: i})
+	}
+
+	return fields
+}
+
+// parseCSVValue converts a CSV column into the given field value
+func parseCSVValue(val reflect.Value, str string) error {
+	switch val.Kind() {
+	case reflect.String:
+		val.SetString(str)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return err
+		}
+		val.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(str, 10, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(str, 10, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetUint(u)
+
+	default:
+		f, err := strconv.ParseFloat(str, val.Type().Bits())
+		if err != nil {
+			return err
+		}
+		val.SetFloat(f)
+	}
+
+	return nil
+}
+
+// formatCSVValue converts a field value into a CSV column
+func formatCSVValue(val reflect.Value) string {
+	switch val.Kind() {
+	case reflect.String:
+		return val.String()
+
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10)
+
+	default:
+		return strconv.FormatFloat(val.Float(), 'g', -1, val.Type().Bits())
+	}
+}
+
+// OfCSVStructs constructs a Stream of structs read from CSV, where the first record is a header of column names.
+// Each column is mapped to the struct field with a matching `csv:"name"` tag, or the field name if it has no tag,
+// and converted to the field type. Columns that do not map to a field are ignored, and fields that have no column are
+// left as zero values.
+// The elements are the same type as proto, which may be a struct or pointer to struct.
+// Panics if proto is not a struct or pointer to struct, or has a mapped field that is not a string, bool, int, uint, or float.
+// The Stream panics with an error during iteration if the CSV cannot be read, or a column cannot be converted.
+func OfCSVStructs(r io.Reader, proto interface{}) Stream {
+	var (
+		typ   = reflect.TypeOf(proto)
+		isPtr = (typ != nil) && (typ.Kind() == reflect.Ptr)
+	)
+
+	if isPtr {
+		typ = typ.Elem()
+	}
+	if (typ == nil) || (typ.Kind() != reflect.Struct) {
+		panic(ErrCSVProtoNotStruct)
+	}
+
+	var (
+		fields       = csvFields(typ)
+		reader       = csv.NewReader(r)
+		columnFields []*csvField
+		headerRead   bool
+		recordsRead  = 1
+		readRecord   = func() []string {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				panic(err)
+			}
+
+			return record
+		}
+	)
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				if !headerRead {
+					headerRead = true
+
+					header := readRecord()
+					if header == nil {
+						return nil, false
+					}
+
+					columnFields = make([]*csvField, len(header))
+					for i, name := range header {
+						for j := range fields {
+							if fields[j].name == name {
+								columnFields[i] = &fields[j]
+								break
+							}
+						}
+					}
+				}
+
+				record := readRecord()
+				if record == nil {
+					return nil, false
+				}
+				recordsRead++
+
+				ptr := reflect.New(typ)
+				for i, column := range record {
+					if (i < len(columnFields)) && (columnFields[i] != nil) {
+						if err := parseCSVValue(ptr.Elem().Field(columnFields[i].index), column); err != nil {
+							panic(fmt.Errorf("record %d, column %s: %w", recordsRead, columnFields[i].name, err))
+						}
+					}
+				}
+
+				if isPtr {
+					return ptr.Interface(), true
+				}
+
+				return ptr.Elem().Interface(), true
+			},
+		),
+		true,
+	)
+}
+
+// ToCSVStructs writes a Stream of structs or pointers to structs as CSV, with a header record of column names.
+// The columns are determined by the type of the first element, with the same mapping as OfCSVStructs.
+// Nothing is written if the Stream is empty.
+// The output is flushed according to the given policies, see FlushPolicy.
+// Returns an error if an element is not the same type as the first element, or a nil pointer, or if writing fails, in
+// which case no further elements are written, else any error from closing the Stream.
+// Panics if the first element is not a struct or pointer to struct, or has a mapped field that is not a string, bool,
+// int, uint, or float.
+func ToCSVStructs(w io.Writer, elements Stream, policies ...FlushPolicy) (err error) {
+	term := elements.AndThen().terminal("ToCSVStructs")
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var (
+		sw     = newSinkWriter(w, policies)
+		writer = csv.NewWriter(sw)
+		typ    reflect.Type
+		fields []csvField
+		record []string
+	)
+
+	writeElement := func(element interface{}) error {
+		if typ == nil {
+			typ = reflect.TypeOf(element)
+			structTyp := typ
+			if (structTyp != nil) && (structTyp.Kind() == reflect.Ptr) {
+				structTyp = structTyp.Elem()
+			}
+			if (structTyp == nil) || (structTyp.Kind() != reflect.Struct) {
+				panic(ErrCSVProtoNotStruct)
+			}
+
+			fields = csvFields(structTyp)
+			record = make([]string, len(fields))
+			for i, field := range fields {
+				record[i] = field.name
+			}
+
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+
+		val := reflect.ValueOf(element)
+		if !val.IsValid() {
+			return fmt.Errorf("element is nil, not of type %s", typ)
+		}
+		if val.Type() != typ {
+			return fmt.Errorf("element of type %s is not of type %s", val.Type(), typ)
+		}
+
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return fmt.Errorf("element is a nil %s", typ)
+			}
+			val = val.Elem()
+		}
+
+		for i, field := range fields {
+			record[i] = formatCSVValue(val.Field(field.index))
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+
+		sw.elementWritten()
+		return nil
+	}
+
+	for it := term.Iter(); (err == nil) && it.Next(); {
+		err = writeElement(it.Value())
+	}
+
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if flushErr := sw.flush(); err == nil {
+		err = flushErr
+	}
+
+	return err
+}
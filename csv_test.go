@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type csvPerson struct {
+	Name    string  `csv:"name"`
+	Age     uint8   `csv:"age"`
+	Balance float64 `csv:"balance"`
+	Active  bool
+	Ignored int `csv:"-"`
+	private int
+}
+
+func TestOfCSVStructs(t *testing.T) {
+	// Empty
+	assert.Equal(t, []interface{}{}, OfCSVStructs(strings.NewReader(""), csvPerson{}).AndThen().ToSlice())
+
+	// Header only
+	assert.Equal(t, []interface{}{}, OfCSVStructs(strings.NewReader("name,age\n"), csvPerson{}).AndThen().ToSlice())
+
+	// Columns in any order, with unknown and missing columns
+	input := "age,unknown,name,Active\n30,x,Alice,true\n40,y,Bob,false\n"
+	assert.Equal(
+		t,
+		[]csvPerson{{Name: "Alice", Age: 30, Active: true}, {Name: "Bob", Age: 40}},
+		OfCSVStructs(strings.NewReader(input), csvPerson{}).AndThen().ToSliceOf(csvPerson{}),
+	)
+
+	// Pointer proto
+	assert.Equal(
+		t,
+		[]*csvPerson{{Name: "Alice", Balance: 1.5}},
+		OfCSVStructs(strings.NewReader("name,balance\nAlice,1.5\n"), &csvPerson{}).AndThen().ToSliceOf(&csvPerson{}),
+	)
+
+	// Conversion error
+	func() {
+		defer func() {
+			err := recover().(error)
+			assert.Contains(t, err.Error(), "record 3, column age: ")
+		}()
+
+		OfCSVStructs(strings.NewReader("name,age\nAlice,30\nBob,300\n"), csvPerson{}).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Invalid proto
+	func() {
+		defer func() {
+			assert.Equal(t, ErrCSVProtoNotStruct, recover())
+		}()
+
+		OfCSVStructs(strings.NewReader(""), 1)
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Unsupported field type
+	func() {
+		defer func() {
+			assert.Equal(t, "field Tags of type []string cannot be mapped to a CSV column", recover())
+		}()
+
+		OfCSVStructs(strings.NewReader(""), struct{ Tags []string }{})
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestToCSVStructs(t *testing.T) {
+	// Empty
+	buf := &bytes.Buffer{}
+	assert.Nil(t, ToCSVStructs(buf, Of()))
+	assert.Equal(t, "", buf.String())
+
+	// Structs
+	assert.Nil(t, ToCSVStructs(buf, Of(csvPerson{"Alice", 30, 1.5, true, 1, 2}, csvPerson{Name: "Bob, Jr."})))
+	assert.Equal(t, "name,age,balance,Active\nAlice,30,1.5,true\n\"Bob, Jr.\",0,0,false\n", buf.String())
+
+	// Round trip of pointers
+	buf.Reset()
+	people := []interface{}{&csvPerson{Name: "Alice", Age: 30}, &csvPerson{Name: "Bob", Balance: -2.25}}
+	assert.Nil(t, ToCSVStructs(buf, Of(people...)))
+	assert.Equal(t, people, OfCSVStructs(buf, &csvPerson{}).AndThen().ToSlice())
+
+	// Mismatched types
+	buf.Reset()
+	assert.EqualError(
+		t,
+		ToCSVStructs(buf, Of(csvPerson{Name: "Alice"}, &csvPerson{})),
+		"element of type *gostream.csvPerson is not of type gostream.csvPerson",
+	)
+	assert.Equal(t, "name,age,balance,Active\nAlice,0,0,false\n", buf.String())
+
+	assert.EqualError(t, ToCSVStructs(buf, Of(&csvPerson{}, (*csvPerson)(nil))), "element is a nil *gostream.csvPerson")
+	assert.EqualError(t, ToCSVStructs(buf, Of(csvPerson{}, nil)), "element is nil, not of type gostream.csvPerson")
+
+	// Close error
+	buf.Reset()
+	assert.EqualError(
+		t,
+		ToCSVStructs(buf, Of(csvPerson{Name: "Alice"}).OnClose(func() error { return errors.New("close") })),
+		"close",
+	)
+	assert.Equal(t, "name,age,balance,Active\nAlice,0,0,false\n", buf.String())
+
+	// A write error is returned instead of a close error
+	assert.EqualError(
+		t,
+		ToCSVStructs(buf, Of(csvPerson{}, nil).OnClose(func() error { return errors.New("close") })),
+		"element is nil, not of type gostream.csvPerson",
+	)
+}
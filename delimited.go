@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/bantling/goiter"
+)
+
+const (
+	// MaxDelimitedSize is the largest message length accepted by OfProtoDelimited, to guard against corrupt input
+	MaxDelimitedSize = 64 * 1024 * 1024
+)
+
+// OfProtoDelimited constructs a Stream of messages read using the standard protobuf framing, where each message is
+// preceded by its length as a varint, such as those written by WriteDelimited or Java's writeDelimitedTo.
+// The unmarshal function decodes the bytes of one message, which avoids a dependency on a particular protobuf library:
+//
+//	OfProtoDelimited(r, func(b []byte) (interface{}, error) {
+//		msg := &pb.Event{}
+//		return msg, proto.Unmarshal(b, msg)
+//	})
+//
+// If r is not an io.ByteReader, it is wrapped in a bufio.Reader, which may read past the last message.
+// The Stream panics with an error during iteration if the input is truncated, a length exceeds MaxDelimitedSize, or a
+// message cannot be unmarshalled.
+func OfProtoDelimited(r io.Reader, unmarshal func(b []byte) (interface{}, error)) Stream {
+	br, ok := r.(interface {
+		io.Reader
+		io.ByteReader
+	})
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				size, err := binary.ReadUvarint(br)
+				if err == io.EOF {
+					return nil, false
+				}
+				if err != nil {
+					panic(err)
+				}
+
+				if size > MaxDelimitedSize {
+					panic(fmt.Errorf("delimited message length %d exceeds the maximum of %d", size, MaxDelimitedSize))
+				}
+
+				buf := make([]byte, size)
+				if _, err = io.ReadFull(br, buf); err != nil {
+					if err == io.EOF {
+						err = io.ErrUnexpectedEOF
+					}
+
+					panic(err)
+				}
+
+				msg, err := unmarshal(buf)
+				if err != nil {
+					panic(err)
+				}
+
+				return msg, true
+			},
+		),
+		true,
+	)
+}
+
+// WriteDelimited writes each element of a Stream using the standard protobuf framing read by OfProtoDelimited.
+// The marshal function encodes one element, such as a func that calls proto.Marshal.
+// The output is flushed according to the given policies, see FlushPolicy.
+// Returns the first error marshalling or writing an element, in which case no further elements are written, else any
+// error from closing the Stream.
+func WriteDelimited(
+	w io.Writer,
+	elements Stream,
+	marshal func(element interface{}) ([]byte, error),
+	policies ...FlushPolicy,
+) (err error) {
+	term := elements.AndThen().terminal("WriteDelimited")
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var (
+		sw   = newSinkWriter(w, policies)
+		size = make([]byte, binary.MaxVarintLen64)
+	)
+
+	for it := term.Iter(); (sw.err == nil) && it.Next(); {
+		var msg []byte
+		if msg, sw.err = marshal(it.Value()); sw.err != nil {
+			break
+		}
+
+		sw.write(size[:binary.PutUvarint(size, uint64(len(msg)))])
+		sw.write(msg)
+		sw.elementWritten()
+	}
+
+	err = sw.flush()
+	return err
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelimited(t *testing.T) {
+	var (
+		marshal = func(element interface{}) ([]byte, error) {
+			if element == "bad" {
+				return nil, errors.New("bad element")
+			}
+
+			return []byte(element.(string)), nil
+		}
+		unmarshal = func(b []byte) (interface{}, error) {
+			if string(b) == "bad" {
+				return nil, errors.New("bad message")
+			}
+
+			return string(b), nil
+		}
+		buf = &bytes.Buffer{}
+	)
+
+	// Empty
+	assert.Nil(t, WriteDelimited(buf, Of(), marshal))
+	assert.Equal(t, 0, buf.Len())
+	assert.Equal(t, []interface{}{}, OfProtoDelimited(buf, unmarshal).AndThen().ToSlice())
+
+	// Framing
+	long := strings.Repeat("x", 200)
+	assert.Nil(t, WriteDelimited(buf, Of("", "ab", long), marshal))
+	assert.Equal(t, "\x00\x02ab\xc8\x01"+long, buf.String())
+	assert.Equal(t, []interface{}{"", "ab", long}, OfProtoDelimited(buf, unmarshal).AndThen().ToSlice())
+
+	// Reader that is not an io.ByteReader
+	assert.Nil(t, WriteDelimited(buf, Of("a", "b"), marshal))
+	assert.Equal(t, []interface{}{"a", "b"}, OfProtoDelimited(io.MultiReader(buf), unmarshal).AndThen().ToSlice())
+
+	// Marshal error stops writing
+	buf.Reset()
+	assert.EqualError(t, WriteDelimited(buf, Of("a", "bad", "c"), marshal), "bad element")
+	assert.Equal(t, "\x01a", buf.String())
+
+	// Close error
+	buf.Reset()
+	assert.EqualError(t, WriteDelimited(buf, Of("a").OnClose(func() error { return errors.New("close") }), marshal), "close")
+	assert.Equal(t, "\x01a", buf.String())
+
+	// A write error is returned instead of a close error
+	buf.Reset()
+	assert.EqualError(
+		t,
+		WriteDelimited(buf, Of("bad").OnClose(func() error { return errors.New("close") }), marshal),
+		"bad element",
+	)
+
+	// Read errors
+	for input, msg := range map[string]string{
+		"\x03bad":              "bad message",
+		"\x05ab":               io.ErrUnexpectedEOF.Error(),
+		"\x80":                 io.ErrUnexpectedEOF.Error(),
+		"\x80\x80\x80\x80\x01": "delimited message length 268435456 exceeds the maximum of 67108864",
+	} {
+		func() {
+			defer func() {
+				assert.EqualError(t, recover().(error), msg)
+			}()
+
+			OfProtoDelimited(strings.NewReader(input), unmarshal).AndThen().ToSlice()
+			assert.Fail(t, "Must panic")
+		}()
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"reflect"
+)
+
+// DiffChange is an element whose key occurs in both Streams given to Diff, but whose old and new elements are not equal
+type DiffChange struct {
+	Old interface{}
+	New interface{}
+}
+
+// DiffResult is the result of Diff
+type DiffResult struct {
+	// Added are the new elements whose key does not occur in the old elements, in new order
+	Added []interface{}
+	// Removed are the old elements whose key does not occur in the new elements, in old order
+	Removed []interface{}
+	// Changed are the elements whose key occurs in both, but whose elements are not equal, in new order
+	Changed []DiffChange
+}
+
+// Diff compares old and new elements by key, and returns the elements that were added, removed, and changed, such as to
+// reconcile a local cache with a remote list.
+// Elements with the same key are compared with the optional equal function, which defaults to reflect.DeepEqual.
+// Keys are expected to be unique within each Stream - if a key occurs more than once, the last element with that key is used.
+// Both Streams are read in full.
+// Panics if either Stream is infinite.
+func Diff(
+	oldElements, newElements Stream,
+	key func(element interface{}) (key interface{}),
+	equal ...func(oldElement, newElement interface{}) bool,
+) DiffResult {
+	oldElements.AndThen().panicIfInfinite()
+	newElements.AndThen().panicIfInfinite()
+
+	eq := reflect.DeepEqual
+	if len(equal) > 0 {
+		eq = equal[0]
+	}
+
+	var (
+		result   DiffResult
+		oldByKey = NewOrderedMap()
+		newByKey = NewOrderedMap()
+	)
+
+	oldElements.AndThen().ForEach(func(element interface{}) { oldByKey.Put(key(element), element) })
+	newElements.AndThen().ForEach(func(element interface{}) { newByKey.Put(key(element), element) })
+
+	for _, k := range newByKey.Keys() {
+		newElement, _ := newByKey.Get(k)
+
+		if oldElement, ok := oldByKey.Get(k); !ok {
+			result.Added = append(result.Added, newElement)
+		} else if !eq(oldElement, newElement) {
+			result.Changed = append(result.Changed, DiffChange{Old: oldElement, New: newElement})
+		}
+	}
+
+	for _, k := range oldByKey.Keys() {
+		if !newByKey.Has(k) {
+			oldElement, _ := oldByKey.Get(k)
+			result.Removed = append(result.Removed, oldElement)
+		}
+	}
+
+	return result
+}
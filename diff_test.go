@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	type item struct {
+		id   int
+		name string
+	}
+
+	id := func(element interface{}) interface{} { return element.(item).id }
+
+	assert.Equal(t, DiffResult{}, Diff(Of(), Of(), id))
+
+	assert.Equal(
+		t,
+		DiffResult{
+			Added:   []interface{}{item{4, "d"}, item{5, "e"}},
+			Removed: []interface{}{item{1, "a"}},
+			Changed: []DiffChange{{Old: item{3, "c"}, New: item{3, "C"}}},
+		},
+		Diff(
+			Of(item{1, "a"}, item{2, "b"}, item{3, "c"}),
+			Of(item{4, "d"}, item{3, "C"}, item{2, "b"}, item{5, "e"}),
+			id,
+		),
+	)
+
+	// Custom equality
+	assert.Equal(
+		t,
+		DiffResult{},
+		Diff(
+			Of(item{1, "a"}),
+			Of(item{1, "A"}),
+			id,
+			func(oldElement, newElement interface{}) bool {
+				return strings.EqualFold(oldElement.(item).name, newElement.(item).name)
+			},
+		),
+	)
+
+	// Duplicate keys use the last element
+	assert.Equal(
+		t,
+		DiffResult{Changed: []DiffChange{{Old: item{1, "b"}, New: item{1, "c"}}}},
+		Diff(Of(item{1, "a"}, item{1, "b"}), Of(item{1, "c"}), id),
+	)
+
+	func() {
+		defer func() {
+			assert.Equal(t, ErrInfiniteFinisher, recover())
+		}()
+
+		Diff(Of(), Iterate(0, func(element interface{}) interface{} { return element }), id)
+		assert.Fail(t, "Must panic")
+	}()
+}
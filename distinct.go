@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+)
+
+const (
+	// ErrDistinctUnhashable is the format of the message thrown when Distinct or PreDistinct is given a StateStore and
+	// an element that cannot be a map key, such as a map or slice
+	ErrDistinctUnhashable = "Distinct cannot store elements of type %T in a StateStore, as they cannot be map keys; use DistinctFunc instead"
+)
+
+// maxDeepHashDepth is the depth beyond which deepHash only hashes the kind of a value, which keeps cyclic values finite
+const maxDeepHashDepth = 8
+
+// isHashable is true if the element can be used as a map key.
+// A struct, array, or interface type can be comparable yet hold an interface value whose dynamic type is not, which is
+// only detected by trying it.
+func isHashable(element interface{}) (hashable bool) {
+	if element == nil {
+		return true
+	}
+
+	typ := reflect.TypeOf(element)
+	if !typ.Comparable() {
+		return false
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Interface:
+		defer func() {
+			if recover() != nil {
+				hashable = false
+			}
+		}()
+
+		_ = map[interface{}]bool{element: true}
+	}
+
+	return true
+}
+
+// deepHash returns a hash of an element that is consistent with reflect.DeepEqual: elements that are deeply equal have
+// the same hash, although elements that are not may also have the same hash
+func deepHash(element interface{}) uint64 {
+	h := fnv.New64a()
+	writeDeepHash(h, reflect.ValueOf(element), 0)
+
+	return h.Sum64()
+}
+
+// writeDeepHash writes the kind and contents of a value to a hash, recursing into pointers, interfaces, slices,
+// arrays, maps, and structs. Map entries are combined in an order independent way, since map order is random.
+func writeDeepHash(h hash.Hash64, val reflect.Value, depth int) {
+	var (
+		buf       [8]byte
+		writeUint = func(u uint64) {
+			binary.LittleEndian.PutUint64(buf[:], u)
+			h.Write(buf[:])
+		}
+		writeFloat = func(f float64) {
+			// -0 == 0, so they must hash the same
+			if f == 0 {
+				f = 0
+			}
+
+			writeUint(math.Float64bits(f))
+		}
+	)
+
+	if !val.IsValid() {
+		writeUint(0)
+		return
+	}
+
+	writeUint(uint64(val.Kind()))
+	if depth > maxDeepHashDepth {
+		return
+	}
+
+	switch val.Kind() {
+	case reflect.Bool:
+		if val.Bool() {
+			writeUint(1)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(val.Int()))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(val.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		writeFloat(val.Float())
+
+	case reflect.Complex64, reflect.Complex128:
+		writeFloat(real(val.Complex()))
+		writeFloat(imag(val.Complex()))
+
+	case reflect.String:
+		h.Write([]byte(val.String()))
+
+	case reflect.Ptr, reflect.Interface:
+		if !val.IsNil() {
+			writeDeepHash(h, val.Elem(), depth+1)
+		}
+
+	case reflect.Slice, reflect.Array:
+		writeUint(uint64(val.Len()))
+		for i, n := 0, val.Len(); i < n; i++ {
+			writeDeepHash(h, val.Index(i), depth+1)
+		}
+
+	case reflect.Map:
+		var sum uint64
+		for it := val.MapRange(); it.Next(); {
+			entry := fnv.New64a()
+			writeDeepHash(entry, it.Key(), depth+1)
+			writeDeepHash(entry, it.Value(), depth+1)
+			sum += entry.Sum64()
+		}
+		writeUint(sum)
+
+	case reflect.Struct:
+		for i, n := 0, val.NumField(); i < n; i++ {
+			writeDeepHash(h, val.Field(i), depth+1)
+		}
+	}
+}
+
+// distinctFunc returns a predicate that is true for the first element of each set of equal elements, where elements
+// are grouped into buckets by hash, and compared with equal only within a bucket
+func distinctFunc(
+	hashFn func(element interface{}) uint64,
+	equal func(element1, element2 interface{}) bool,
+) func(element interface{}) bool {
+	buckets := map[uint64][]interface{}{}
+
+	return func(element interface{}) bool {
+		h := hashFn(element)
+		for _, read := range buckets[h] {
+			if equal(read, element) {
+				return false
+			}
+		}
+
+		buckets[h] = append(buckets[h], element)
+		return true
+	}
+}
+
+// distinctFilter returns the predicate used by Distinct and PreDistinct for one iteration, which stores elements in the
+// given StateStore, or in a new MapStateStore if none is given.
+// Elements that cannot be map keys are compared with reflect.DeepEqual when no StateStore is given, and the predicate
+// panics with ErrDistinctUnhashable when one is given.
+// Panics if more than one StateStore is given.
+func distinctFilter(store []StateStore) func(element interface{}) bool {
+	var (
+		alreadyRead = stateStore(store)
+		unhashable  func(element interface{}) bool
+	)
+
+	return func(element interface{}) bool {
+		if !isHashable(element) {
+			if len(store) > 0 {
+				panic(fmt.Sprintf(ErrDistinctUnhashable, element))
+			}
+
+			if unhashable == nil {
+				unhashable = distinctFunc(deepHash, reflect.DeepEqual)
+			}
+
+			return unhashable(element)
+		}
+
+		if alreadyRead.Has(element) {
+			return false
+		}
+
+		alreadyRead.Put(element, true)
+		return true
+	}
+}
+
+// DistinctFunc returns a new Finisher of distinct elements only, for elements of any type, including those that cannot
+// be map keys such as maps and slices.
+// Elements are grouped by the given hash, and compared with equal only when their hashes are the same, so elements
+// that are equal must have the same hash.
+func (fin Finisher) DistinctFunc(
+	hashFn func(element interface{}) uint64,
+	equal func(element1, element2 interface{}) bool,
+) Finisher {
+	return fin.filterEach(func() func(element interface{}) bool { return distinctFunc(hashFn, equal) })
+}
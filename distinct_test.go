@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type distinctTestStruct struct {
+	name  string
+	value interface{}
+}
+
+func TestIsHashable(t *testing.T) {
+	assert.True(t, isHashable(nil))
+	assert.True(t, isHashable(1))
+	assert.True(t, isHashable("a"))
+	assert.True(t, isHashable(distinctTestStruct{"a", 1}))
+	assert.True(t, isHashable([2]interface{}{1, 2}))
+	assert.False(t, isHashable([]int{1}))
+	assert.False(t, isHashable(map[string]int{}))
+	assert.False(t, isHashable(distinctTestStruct{"a", []int{1}}))
+	assert.False(t, isHashable([2]interface{}{1, []int{1}}))
+}
+
+func TestDeepHash(t *testing.T) {
+	type cyclic struct {
+		next *cyclic
+	}
+
+	c := &cyclic{}
+	c.next = c
+
+	for _, pair := range [][2]interface{}{
+		{[]int{1, 2}, []int{1, 2}},
+		{map[string]interface{}{"a": 1, "b": []int{2}}, map[string]interface{}{"b": []int{2}, "a": 1}},
+		{distinctTestStruct{"a", []int{1}}, distinctTestStruct{"a", []int{1}}},
+		{&distinctTestStruct{"a", 1}, &distinctTestStruct{"a", 1}},
+		{[]float64{0}, []float64{math.Copysign(0, -1)}},
+		{c, c},
+	} {
+		assert.True(t, reflect.DeepEqual(pair[0], pair[1]))
+		assert.Equal(t, deepHash(pair[0]), deepHash(pair[1]), "%v", pair)
+	}
+
+	assert.NotEqual(t, deepHash([]int{1, 2}), deepHash([]int{2, 1}))
+	assert.NotEqual(t, deepHash(map[string]int{"a": 1}), deepHash(map[string]int{"a": 2}))
+}
+
+func TestFinisherDistinctUnhashable(t *testing.T) {
+	// Fall back to reflect.DeepEqual
+	assert.Equal(
+		t,
+		[]interface{}{[]int{1}, []int{2}, 3, map[string]int{"a": 1}},
+		Of([]int{1}, []int{2}, 3, []int{1}, map[string]int{"a": 1}, 3, map[string]int{"a": 1}).AndThen().Distinct().ToSlice(),
+	)
+	assert.Equal(
+		t,
+		[]interface{}{[]int{1}, []int{2}},
+		Of([]int{1}, []int{2}, []int{1}).PreDistinct().AndThen().ToSlice(),
+	)
+
+	// Unhashable elements cannot be stored in a StateStore
+	func() {
+		defer func() {
+			assert.Equal(t, fmt.Sprintf(ErrDistinctUnhashable, []int{1}), recover())
+		}()
+
+		Of(1, []int{1}).AndThen().Distinct(MapStateStore{}).ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	func() {
+		defer func() {
+			assert.Equal(t, fmt.Sprintf(ErrDistinctUnhashable, map[int]int{}), recover())
+		}()
+
+		Of(map[int]int{}).PreDistinct(MapStateStore{}).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestFinisherDistinctFunc(t *testing.T) {
+	var (
+		equalCalls int
+		length     = func(element interface{}) uint64 { return uint64(len(element.([]int))) }
+		equal      = func(element1, element2 interface{}) bool {
+			equalCalls++
+			return reflect.DeepEqual(element1, element2)
+		}
+	)
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().DistinctFunc(length, equal).ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{[]int{1}, []int{1, 2}, []int{2}},
+		Of([]int{1}, []int{1, 2}, []int{2}, []int{1}, []int{1, 2}).AndThen().DistinctFunc(length, equal).ToSlice(),
+	)
+
+	// Only elements with the same hash are compared: [2] with [1], [1] with [1], [1, 2] with [1, 2]
+	assert.Equal(t, 3, equalCalls)
+
+	// Each iteration of a replayable Stream starts over
+	fin := Of([]int{1}, []int{1}).Cache().AndThen().DistinctFunc(length, equal)
+	assert.Equal(t, []interface{}{[]int{1}}, fin.ToSlice())
+	assert.Equal(t, []interface{}{[]int{1}}, fin.ToSlice())
+}
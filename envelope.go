@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"github.com/bantling/goiter"
+)
+
+// Envelope is an element of a Stream paired with metadata about where it came from, such as a source offset, filename,
+// line number, or trace ID, as produced by WithEnvelope. The metadata travels with the element through functions
+// adapted by MapEnveloped and FilterEnveloped, so that it is still available in terminals after the value is mapped.
+type Envelope struct {
+	V    interface{}
+	Meta map[string]interface{}
+}
+
+// WithEnvelope returns a Stream of Envelope elements, where V is each element of the given Stream, and Meta is the
+// result of calling meta with the position of the element starting at 0 and the element.
+// The Stream is iterated sequentially by the parallel methods of Finisher, and only transforms applied after
+// WithEnvelope are executed in parallel.
+func WithEnvelope(s Stream, meta func(offset int, element interface{}) map[string]interface{}) Stream {
+	return s.sequentialTransform(
+		func(it *goiter.Iter) *goiter.Iter {
+			offset := 0
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !it.Next() {
+						return nil, false
+					}
+
+					element := it.Value()
+					val := Envelope{V: element, Meta: meta(offset, element)}
+					offset++
+
+					return val, true
+				},
+			)
+		},
+		false,
+	)
+}
+
+// MapEnveloped adapts a func that maps values into a func that maps the value of an Envelope element, for use with Map.
+// The result is an Envelope with the same metadata.
+// Panics if the element is not an Envelope.
+func MapEnveloped(f func(element interface{}) interface{}) func(element interface{}) interface{} {
+	return func(element interface{}) interface{} {
+		env := element.(Envelope)
+		return Envelope{V: f(env.V), Meta: env.Meta}
+	}
+}
+
+// FilterEnveloped adapts a predicate of values into a predicate of the value of an Envelope element, for use with
+// Filter.
+// Panics if the element is not an Envelope.
+func FilterEnveloped(f func(element interface{}) bool) func(element interface{}) bool {
+	return func(element interface{}) bool {
+		return f(element.(Envelope).V)
+	}
+}
+
+// EnvelopeValue maps an Envelope element to its value, for use with Map.
+// Panics if the element is not an Envelope.
+func EnvelopeValue(element interface{}) interface{} {
+	return element.(Envelope).V
+}
+
+// EnvelopeMeta returns the metadata of an Envelope element, for use in terminals.
+// Panics if the element is not an Envelope.
+func EnvelopeMeta(element interface{}) map[string]interface{} {
+	return element.(Envelope).Meta
+}
+
+// Unenvelope returns a Stream of the values of a Stream of Envelope elements.
+// Panics during iteration if an element is not an Envelope.
+func Unenvelope(s Stream) Stream {
+	return s.Map(EnvelopeValue)
+}
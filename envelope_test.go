@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	var (
+		meta = func(offset int, element interface{}) map[string]interface{} {
+			return map[string]interface{}{"file": "in.txt", "line": offset + 1}
+		}
+		double = func(element interface{}) interface{} { return element.(int) * 2 }
+		large  = func(element interface{}) bool { return element.(int) > 4 }
+	)
+
+	assert.Equal(t, []interface{}{}, WithEnvelope(Of(), meta).AndThen().ToSlice())
+
+	// Metadata survives Map and Filter
+	var lines []interface{}
+	assert.Equal(
+		t,
+		[]interface{}{6, 8},
+		Unenvelope(
+			WithEnvelope(Of(1, 2, 3, 4), meta).
+				Map(MapEnveloped(double)).
+				Filter(FilterEnveloped(large)).
+				Peek(func(element interface{}) { lines = append(lines, EnvelopeMeta(element)["line"]) }),
+		).AndThen().ToSlice(),
+	)
+	assert.Equal(t, []interface{}{3, 4}, lines)
+
+	// Metadata is accessible in terminals
+	assert.Equal(
+		t,
+		[]interface{}{
+			Envelope{V: "A", Meta: map[string]interface{}{"file": "in.txt", "line": 1}},
+			Envelope{V: "B", Meta: map[string]interface{}{"file": "in.txt", "line": 2}},
+		},
+		WithEnvelope(Of("a", "b"), meta).
+			Map(MapEnveloped(func(element interface{}) interface{} {
+				return strings.ToUpper(element.(string))
+			})).
+			AndThen().
+			ToSlice(),
+	)
+	assert.Equal(t, "a", EnvelopeValue(Envelope{V: "a"}))
+
+	// Offsets are assigned sequentially before parallel transforms
+	var parallelLines []interface{}
+	for _, element := range WithEnvelope(Of(1, 2, 3, 4), meta).Map(MapEnveloped(double)).AndThen().ParallelToSlice(1) {
+		assert.Equal(t, EnvelopeMeta(element)["line"].(int)*2, EnvelopeValue(element))
+		parallelLines = append(parallelLines, EnvelopeMeta(element)["line"])
+	}
+	assert.Equal(t, []interface{}{1, 2, 3, 4}, parallelLines)
+
+	// Each iteration of a replayable Stream starts at offset 0
+	line := func(element interface{}) interface{} { return EnvelopeMeta(element)["line"] }
+	cached := WithEnvelope(Of("a", "b").Cache(), meta).Map(line)
+	assert.Equal(t, []interface{}{1, 2}, cached.AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, cached.AndThen().ToSlice())
+
+	// An iteration of a replayable Stream starts at offset 0 after a partial iteration
+	snapshot := WithEnvelope(Of("a", "b").Snapshot(), meta).Map(line)
+	it := snapshot.Iter()
+	assert.True(t, it.Next())
+	assert.Equal(t, []interface{}{1, 2}, snapshot.AndThen().ToSlice())
+
+	// FindFirst and a later terminal read the same iteration
+	fin := WithEnvelope(Of("a", "b", "c"), meta).Map(line).AndThen()
+	assert.Equal(t, 1, fin.FindFirst().MustGet())
+	assert.Equal(t, []interface{}{2, 3}, fin.ToSlice())
+}
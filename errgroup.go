@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/bantling/goiter"
+)
+
+// ErrGroup is the subset of the methods of *errgroup.Group from golang.org/x/sync used by the parallel Group terminals,
+// so that parallel processing can join a group managed by a service without this package depending on that module.
+// A group created by errgroup.WithContext should be passed along with its context, so that processing stops when
+// any goroutine of the group fails.
+type ErrGroup interface {
+	Go(f func() error)
+	Wait() error
+}
+
+// errGroup is the ErrGroup used when none is provided, which cancels its context on the first error
+type errGroup struct {
+	wg     sync.WaitGroup
+	cancel func()
+	once   sync.Once
+	err    error
+}
+
+// newErrGroup constructs an errGroup and a context derived from ctx that is cancelled on the first error
+func newErrGroup(ctx context.Context) (*errGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &errGroup{cancel: cancel}, ctx
+}
+
+// Go is ErrGroup.Go
+func (g *errGroup) Go(f func() error) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		if err := f(); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+// Wait is ErrGroup.Wait
+func (g *errGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// recoverError is deferred to convert a panic into an error
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		if e, ok := r.(error); ok {
+			*err = e
+		} else {
+			*err = fmt.Errorf("%v", r)
+		}
+	}
+}
+
+// doParallelGroup is the same as doParallel, except that the goroutines are executed by an ErrGroup, panics are
+// returned as errors, and processing stops when the context is done.
+// If the group is nil, one is created that cancels the context on the first error.
+func doParallelGroup(
+	ctx context.Context,
+	g ErrGroup,
+	source *goiter.Iter,
+	transform func(*goiter.Iter) *goiter.Iter,
+	finisher func(*goiter.Iter) *goiter.Iter,
+	numItems uint,
+	flag ParallelFlags,
+) (flatData []interface{}, err error) {
+	defer recoverError(&err)
+
+	groupCtx := ctx
+	if g == nil {
+		g, groupCtx = newErrGroup(ctx)
+	}
+
+	if transform == nil {
+		// If the transform is nil, there is no transform, just use source vales as is
+		flatData = source.ToSlice()
+	} else {
+		splitData := splitParallel(source, numItems, flag)
+
+		// Execute goroutines in the group, one per row of splitData.
+		// Each goroutine applies the queued operations to each item in its row, until the context is done.
+		for i, row := range splitData {
+			i, row := i, row
+
+			g.Go(func() (err error) {
+				defer recoverError(&err)
+
+				var (
+					it     = transform(goiter.OfElements(row))
+					result = []interface{}{}
+				)
+
+				for {
+					if err = groupCtx.Err(); err != nil {
+						return err
+					}
+
+					if !it.Next() {
+						break
+					}
+
+					result = append(result, it.Value())
+				}
+
+				splitData[i] = result
+				return err
+			})
+		}
+
+		// Wait for all goroutines of the group to complete
+		if err = g.Wait(); err != nil {
+			return nil, err
+		}
+
+		// Combine rows into a single flat slice
+		flatData = goiter.FlattenArraySlice(splitData)
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// If the finisher is non-nil, apply it afterwards - it cannot be done in parallel
+	if finisher != nil {
+		flatData = finisher(goiter.Of(flatData...)).ToSlice()
+	}
+
+	return flatData, err
+}
+
+// parallelGroup is the common implementation of the parallel Group terminals
+func (fin Finisher) parallelGroup(
+	ctx context.Context,
+	name string,
+	g ErrGroup,
+	numItems uint,
+	flag []ParallelFlags,
+) (data []interface{}, err error) {
+	term := fin.terminal(name)
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	fin.panicIfInfinite()
+
+	theFlag := NumberOfGoroutines
+	if len(flag) > 0 {
+		theFlag = flag[0]
+	}
+
+	data, err = doParallelGroup(
+		ctx,
+		g,
+		fin.source.source(),
+		fin.source.transform,
+		fin.transform,
+		numItems,
+		theFlag,
+	)
+	term.elements = uint64(len(data))
+
+	return data, err
+}
+
+// ParallelToStreamGroup is the same as ParallelToStream, except that the goroutines are executed by the given group,
+// or by an internal group that cancels ctx on the first error if the group is nil.
+// A panic in any transform is returned as an error, and processing stops when ctx is done, returning ctx.Err().
+// Since the results are only available once the group is done, the group is waited on, which includes any other
+// goroutines already started in it.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ParallelToStreamGroup(
+	ctx context.Context,
+	g ErrGroup,
+	numItems uint,
+	flag ...ParallelFlags,
+) (Stream, error) {
+	data, err := fin.parallelGroup(ctx, "ParallelToStreamGroup", g, numItems, flag)
+	if err != nil {
+		return Stream{}, err
+	}
+
+	return Of(data...), nil
+}
+
+// ParallelToSliceGroup is the same as ParallelToStreamGroup, except that it returns the data as a slice.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ParallelToSliceGroup(
+	ctx context.Context,
+	g ErrGroup,
+	numItems uint,
+	flag ...ParallelFlags,
+) ([]interface{}, error) {
+	return fin.parallelGroup(ctx, "ParallelToSliceGroup", g, numItems, flag)
+}
+
+// ParallelToSliceOfGroup is the same as ParallelToSliceGroup, except that it returns the data as a slice whose type
+// matches the element value given.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ParallelToSliceOfGroup(
+	ctx context.Context,
+	elementValue interface{},
+	g ErrGroup,
+	numItems uint,
+	flag ...ParallelFlags,
+) (interface{}, error) {
+	data, err := fin.parallelGroup(ctx, "ParallelToSliceOfGroup", g, numItems, flag)
+	if err != nil {
+		return nil, err
+	}
+
+	return goiter.FlattenArraySliceAsType(data, elementValue), nil
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingGroup is an ErrGroup that counts goroutines, and returns the first error
+type countingGroup struct {
+	wg    sync.WaitGroup
+	mutex sync.Mutex
+	calls int
+	err   error
+}
+
+func (g *countingGroup) Go(f func() error) {
+	g.mutex.Lock()
+	g.calls++
+	g.mutex.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		if err := f(); err != nil {
+			g.mutex.Lock()
+			if g.err == nil {
+				g.err = err
+			}
+			g.mutex.Unlock()
+		}
+	}()
+}
+
+func (g *countingGroup) Wait() error {
+	g.wg.Wait()
+	return g.err
+}
+
+func TestStreamParallelGroup(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		double = func(element interface{}) interface{} { return element.(int) * 2 }
+		less   = func(element1, element2 interface{}) bool { return element1.(int) < element2.(int) }
+	)
+
+	// Internal group
+	s, err := Of(3, 1, 2).Map(double).AndThen().Sorted(less).ParallelToStreamGroup(ctx, nil, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{2, 4, 6}, s.AndThen().ToSlice())
+
+	// No transform
+	slice, err := Of(1, 2).AndThen().ParallelToSliceGroup(ctx, nil, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{1, 2}, slice)
+
+	// Provided group
+	g := &countingGroup{}
+	sliceOf, err := Of(1, 2, 3, 4).Map(double).AndThen().ParallelToSliceOfGroup(ctx, 0, g, 2, NumberOfItemsPerGoroutine)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2, 4, 6, 8}, sliceOf)
+	assert.Equal(t, 2, g.calls)
+
+	// Panic in a transform is an error
+	_, err = Of(1, 2, 3).Map(func(element interface{}) interface{} {
+		if element == 2 {
+			panic(errors.New("two"))
+		}
+		return element
+	}).AndThen().ParallelToSliceGroup(ctx, nil, 3)
+	assert.EqualError(t, err, "two")
+
+	_, err = Of(1).Map(func(element interface{}) interface{} {
+		panic("one")
+	}).AndThen().ParallelToSliceGroup(ctx, &countingGroup{}, 1)
+	assert.EqualError(t, err, "one")
+
+	// Cancelled context
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Of(1, 2).Map(double).AndThen().ParallelToSliceGroup(cancelled, nil, 2)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = Of(1, 2).AndThen().ParallelToSliceGroup(cancelled, nil, 2)
+	assert.Equal(t, context.Canceled, err)
+
+	// Close error
+	_, err = Of(1).OnClose(func() error { return errors.New("close") }).AndThen().ParallelToSliceGroup(ctx, nil, 1)
+	assert.EqualError(t, err, "close")
+
+	// Infinite
+	func() {
+		defer func() {
+			assert.Equal(t, ErrInfiniteFinisher, recover())
+		}()
+
+		Iterate(1, double).AndThen().ParallelToSliceGroup(ctx, nil, 1)
+		assert.Fail(t, "Must panic")
+	}()
+}
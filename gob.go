@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"encoding/gob"
+	"io"
+	"reflect"
+
+	"github.com/bantling/goiter"
+)
+
+const (
+	// ErrGobProtoNil is thrown when OfGob is given a nil proto
+	ErrGobProtoNil = "proto cannot be nil"
+)
+
+// OfGob constructs a Stream of elements decoded from gob values written by Finisher.ToGob, so that the results of a
+// Stream can be checkpointed and resumed later.
+// The elements are the same type as proto, where a pointer proto produces pointers to newly decoded values.
+// Panics if proto is nil.
+// The Stream panics with an error during iteration if a value cannot be decoded.
+func OfGob(r io.Reader, proto interface{}) Stream {
+	typ := reflect.TypeOf(proto)
+	if typ == nil {
+		panic(ErrGobProtoNil)
+	}
+
+	isPtr := typ.Kind() == reflect.Ptr
+	if isPtr {
+		typ = typ.Elem()
+	}
+
+	dec := gob.NewDecoder(r)
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				ptr := reflect.New(typ)
+				if err := dec.DecodeValue(ptr); err != nil {
+					if err == io.EOF {
+						return nil, false
+					}
+
+					panic(err)
+				}
+
+				if isPtr {
+					return ptr.Interface(), true
+				}
+
+				return ptr.Elem().Interface(), true
+			},
+		),
+		true,
+	)
+}
+
+// ToGob writes each element as a gob value, which can be read back with OfGob.
+// Pointers are written as the values they point to.
+// Returns the first error encoding an element or closing the Stream, in which case no further elements are read.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ToGob(w io.Writer) (err error) {
+	term := fin.terminal("ToGob")
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	enc := gob.NewEncoder(w)
+	for it := term.Iter(); it.Next(); {
+		if err = enc.Encode(it.Value()); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type gobPoint struct {
+	X, Y int
+}
+
+func TestGob(t *testing.T) {
+	// Empty
+	buf := &bytes.Buffer{}
+	assert.Nil(t, Of().AndThen().ToGob(buf))
+	assert.Equal(t, 0, buf.Len())
+	assert.Equal(t, []interface{}{}, OfGob(buf, 0).AndThen().ToSlice())
+
+	// Values
+	assert.Nil(t, Of(gobPoint{1, 2}, &gobPoint{3, 4}).AndThen().ToGob(buf))
+	assert.Equal(t, []gobPoint{{1, 2}, {3, 4}}, OfGob(buf, gobPoint{}).AndThen().ToSliceOf(gobPoint{}))
+
+	// Pointers
+	assert.Nil(t, Of(1, 2, 3).AndThen().ToGob(buf))
+	ptrs := OfGob(buf, new(int)).AndThen().ToSliceOf(new(int)).([]*int)
+	assert.Equal(t, 3, len(ptrs))
+	assert.Equal(t, 3, *ptrs[2])
+
+	// Encoding error
+	assert.Error(t, Of(func() {}).AndThen().ToGob(buf))
+
+	// Close error
+	buf.Reset()
+	assert.EqualError(t, Of(1).OnClose(func() error { return errors.New("close") }).AndThen().ToGob(buf), "close")
+
+	// Decoding error
+	func() {
+		defer func() {
+			assert.NotNil(t, recover())
+		}()
+
+		OfGob(strings.NewReader("garbage"), 0).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Nil proto
+	func() {
+		defer func() {
+			assert.Equal(t, ErrGobProtoNil, recover())
+		}()
+
+		OfGob(buf, nil)
+		assert.Fail(t, "Must panic")
+	}()
+}
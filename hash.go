@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"hash"
+)
+
+// Hash writes the encoding of each element to the given hash in order, and returns the resulting sum, for fingerprinting
+// the content of a Stream without buffering it.
+// The hash is not reset first, so a hash that already contains data includes it in the sum.
+// Since the encodings are concatenated, an encoding that is not self delimiting, such as a variable length string, should
+// include a delimiter or length to avoid different Streams having the same sum.
+// Panics if the Finisher is infinite.
+func (fin Finisher) Hash(h hash.Hash, encode func(element interface{}) []byte) []byte {
+	term := fin.terminal("Hash")
+	defer term.done()
+
+	for it := term.Iter(); it.Next(); {
+		h.Write(encode(it.Value()))
+	}
+
+	return h.Sum(nil)
+}
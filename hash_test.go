@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash(t *testing.T) {
+	encode := func(element interface{}) []byte { return []byte(element.(string) + "\n") }
+
+	empty := sha256.Sum256(nil)
+	assert.Equal(t, empty[:], Of().AndThen().Hash(sha256.New(), encode))
+
+	sum := sha256.Sum256([]byte("a\nbc\n"))
+	assert.Equal(t, sum[:], Of("a", "bc").AndThen().Hash(sha256.New(), encode))
+
+	// Different streams with the same concatenation have different sums due to the delimiter
+	assert.NotEqual(t, sum[:], Of("ab", "c").AndThen().Hash(sha256.New(), encode))
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// JSONStreamFormat is the format of the response written by ServeJSONStream
+type JSONStreamFormat uint
+
+const (
+	// JSONArray is the default, and writes the elements as a single JSON array
+	JSONArray JSONStreamFormat = iota
+	// NDJSON writes each element as a JSON value followed by a newline
+	NDJSON
+)
+
+// JSONStreamOptions are the options of ServeJSONStream
+type JSONStreamOptions struct {
+	// Context stops the response when it is done, and is typically the request context.
+	// If nil, the response cannot be cancelled.
+	Context context.Context
+	// Format is the format of the response
+	Format JSONStreamFormat
+	// FlushPolicies determine how often a chunk is sent to the client. If empty, every element is sent as it is written.
+	FlushPolicies []FlushPolicy
+}
+
+// ServeJSONStream writes the elements of a Finisher to an HTTP response as JSON, in the format given by the options.
+// The response is written as the elements are read, and flushed according to the flush policies, so the client receives
+// the response in chunks, and the result set does not have to fit in memory.
+// The Content-Type header is set to application/json or application/x-ndjson, unless it has already been set.
+//
+// If the context is done, no further elements are read, and a JSON array is terminated, so that the response is valid.
+// Since the context stops the response, the Finisher may be infinite if a context is provided.
+//
+// Returns the first error encoding an element, writing the response, or closing the Stream, or else the context error
+// if the context is done.
+// Panics if the Finisher is infinite and no context is provided.
+func ServeJSONStream(w http.ResponseWriter, fin Finisher, opts JSONStreamOptions) error {
+	var (
+		contentType = "application/json"
+		sink        Sink
+	)
+
+	if opts.Format == NDJSON {
+		contentType = "application/x-ndjson"
+		sink = WriteSink(
+			w,
+			func(element interface{}) ([]byte, error) {
+				encoded, err := json.Marshal(element)
+				return append(encoded, '\n'), err
+			},
+			opts.FlushPolicies...,
+		)
+	} else {
+		sink = NewJSONArraySink(w, opts.FlushPolicies...)
+	}
+
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	if opts.Context != nil {
+		fin = fin.TakeUntil(opts.Context.Done())
+	}
+
+	err := fin.ToSink(sink)
+	if (err == nil) && (opts.Context != nil) {
+		err = opts.Context.Err()
+	}
+
+	return err
+}
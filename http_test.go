@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeJSONStream(t *testing.T) {
+	// JSON array
+	rec := httptest.NewRecorder()
+	assert.Nil(t, ServeJSONStream(rec, Of(1, "a").AndThen(), JSONStreamOptions{}))
+	assert.Equal(t, `[1,"a"]`, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.True(t, rec.Flushed)
+
+	// NDJSON, with an existing content type
+	rec = httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "text/plain")
+	assert.Nil(t, ServeJSONStream(rec, Of(1, "a").AndThen(), JSONStreamOptions{Format: NDJSON}))
+	assert.Equal(t, "1\n\"a\"\n", rec.Body.String())
+	assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+
+	rec = httptest.NewRecorder()
+	assert.Nil(t, ServeJSONStream(rec, Of().AndThen(), JSONStreamOptions{Format: NDJSON}))
+	assert.Equal(t, "", rec.Body.String())
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	// Cancelling the context stops an infinite stream, and terminates the array
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		fin         = Iterate(0, func(element interface{}) interface{} {
+			if element.(int) == 2 {
+				cancel()
+			}
+			return element.(int) + 1
+		}).AndThen()
+	)
+	defer cancel()
+
+	rec = httptest.NewRecorder()
+	assert.Equal(t, context.Canceled, ServeJSONStream(rec, fin, JSONStreamOptions{Context: ctx, FlushPolicies: []FlushPolicy{FlushEvery(2)}}))
+	assert.Equal(t, "[1,2,3]", rec.Body.String())
+
+	// Encoding error
+	rec = httptest.NewRecorder()
+	assert.NotNil(t, ServeJSONStream(rec, Of(1, func() {}).AndThen(), JSONStreamOptions{Format: NDJSON}))
+	assert.Equal(t, "1\n", rec.Body.String())
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"github.com/bantling/goiter"
+)
+
+// Indexed is an element of a Stream paired with its original position, as produced by Enumerate
+type Indexed struct {
+	I int
+	V interface{}
+}
+
+// Enumerate returns a Stream of Indexed elements, where I is the position of each element in the given Stream starting
+// at 0, and V is the element. Since the position travels with the element, the original order can be recovered after
+// sorting or grouping with Finisher.Sorted(IndexedLess), and the value recovered with Unindex or IndexedValue.
+// The Stream is iterated sequentially by the parallel methods of Finisher, and only transforms applied after
+// Enumerate are executed in parallel.
+func Enumerate(s Stream) Stream {
+	return s.sequentialTransform(
+		func(it *goiter.Iter) *goiter.Iter {
+			index := 0
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !it.Next() {
+						return nil, false
+					}
+
+					val := Indexed{I: index, V: it.Value()}
+					index++
+
+					return val, true
+				},
+			)
+		},
+		false,
+	)
+}
+
+// IndexedValue maps an Indexed element to its value, for use with Map.
+// Panics if the element is not an Indexed.
+func IndexedValue(element interface{}) interface{} {
+	return element.(Indexed).V
+}
+
+// IndexedLess compares Indexed elements by position, for use with Sorted to restore their original order.
+// Panics if the elements are not Indexed.
+func IndexedLess(element1, element2 interface{}) bool {
+	return element1.(Indexed).I < element2.(Indexed).I
+}
+
+// Unindex returns a Stream of the values of a Stream of Indexed elements.
+// Panics during iteration if an element is not an Indexed.
+func Unindex(s Stream) Stream {
+	return s.Map(IndexedValue)
+}
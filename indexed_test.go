@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumerate(t *testing.T) {
+	assert.Equal(t, []interface{}{}, Enumerate(Of()).AndThen().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{Indexed{0, "a"}, Indexed{1, "b"}},
+		Enumerate(Of("a", "b")).AndThen().ToSlice(),
+	)
+
+	// Index survives a sort by value
+	sorted := Enumerate(Of("c", "a", "b")).
+		AndThen().
+		Sorted(func(element1, element2 interface{}) bool {
+			return element1.(Indexed).V.(string) < element2.(Indexed).V.(string)
+		}).
+		ToSlice()
+	assert.Equal(t, []interface{}{Indexed{1, "a"}, Indexed{2, "b"}, Indexed{0, "c"}}, sorted)
+
+	// Original order restored
+	assert.Equal(
+		t,
+		[]interface{}{"c", "a", "b"},
+		Unindex(Of(sorted...).AndThen().Sorted(IndexedLess).ToStream()).AndThen().ToSlice(),
+	)
+
+	// Infinite Stream is still infinite
+	assert.Equal(
+		t,
+		[]interface{}{Indexed{0, 1}, Indexed{1, 1}},
+		Enumerate(Iterate(1, func(element interface{}) interface{} { return element })).AndThen().Limit(2).ToSlice(),
+	)
+
+	// Parallel
+	assert.Equal(t, []interface{}{"a", "b", "c"}, Unindex(Enumerate(Of("a", "b", "c"))).AndThen().ParallelToSlice(2))
+
+	// Each iteration of a replayable Stream starts at 0
+	cached := Enumerate(Of("a", "b").Cache())
+	assert.Equal(t, []interface{}{Indexed{0, "a"}, Indexed{1, "b"}}, cached.AndThen().ToSlice())
+	assert.Equal(t, []interface{}{Indexed{0, "a"}, Indexed{1, "b"}}, cached.AndThen().ToSlice())
+
+	// An iteration of a replayable Stream starts at 0 after a partial iteration
+	snapshot := Enumerate(Of(10, 20, 30).Snapshot())
+	it := snapshot.Iter()
+	assert.True(t, it.Next())
+	assert.Equal(t, []interface{}{Indexed{0, 10}, Indexed{1, 20}, Indexed{2, 30}}, snapshot.AndThen().ToSlice())
+
+	// FindFirst and a later terminal read the same iteration
+	fin := Enumerate(Of("a", "b", "c")).AndThen()
+	assert.Equal(t, Indexed{0, "a"}, fin.FindFirst().MustGet())
+	assert.Equal(t, []interface{}{Indexed{1, "b"}, Indexed{2, "c"}}, fin.ToSlice())
+}
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"github.com/bantling/goiter"
+)
+
+// JoinType indicates which unmatched elements are included in the result of Join
+type JoinType uint
+
+const (
+	// InnerJoin is the default, and only includes elements that have a match on the other side
+	InnerJoin JoinType = iota
+	// LeftJoin also includes left elements that have no match on the right side
+	LeftJoin
+	// OuterJoin also includes left and right elements that have no match on the other side
+	OuterJoin
+)
+
+// Join returns a Stream of combine(leftElement, rightElement) for every left and right element whose keys are equal.
+// Use PairOf as the combiner to get a Stream of Pair elements.
+// The kind of join is given by the optional JoinType value, which defaults to InnerJoin.
+// Unmatched elements of a LeftJoin or OuterJoin are combined with nil for the missing side.
+//
+// Both Streams are read in full when the first joined element is read, and the smaller side is hashed by key.
+// Regardless of which side is hashed, the result is in left order, where each left element is combined with all of
+// its matching right elements in right order. Unmatched right elements of an OuterJoin follow in right order.
+//
+// Panics if either Stream is infinite.
+func Join(
+	left, right Stream,
+	leftKey, rightKey func(element interface{}) (key interface{}),
+	combine func(leftElement, rightElement interface{}) interface{},
+	joinType ...JoinType,
+) Stream {
+	left.AndThen().panicIfInfinite()
+	right.AndThen().panicIfInfinite()
+
+	theJoinType := InnerJoin
+	if len(joinType) > 0 {
+		theJoinType = joinType[0]
+	}
+
+	var joinedIter *goiter.Iter
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				if joinedIter == nil {
+					joinedIter = goiter.OfElements(
+						doJoin(
+							left.AndThen().ToSlice(),
+							right.AndThen().ToSlice(),
+							leftKey,
+							rightKey,
+							combine,
+							theJoinType,
+						),
+					)
+				}
+
+				// Return next joined element
+				if joinedIter.Next() {
+					return joinedIter.Value(), true
+				}
+
+				return nil, false
+			},
+		),
+		true,
+	)
+}
+
+// doJoin does the grunt work of joining, returning a slice of combined elements
+func doJoin(
+	leftElements, rightElements []interface{},
+	leftKey, rightKey func(element interface{}) (key interface{}),
+	combine func(leftElement, rightElement interface{}) interface{},
+	joinType JoinType,
+) []interface{} {
+	var (
+		// rightMatches[i] is the indexes of all right elements that match left element i
+		rightMatches = make([][]int, len(leftElements))
+		// rightMatched[j] is true if right element j matches any left element
+		rightMatched = make([]bool, len(rightElements))
+	)
+
+	if len(leftElements) < len(rightElements) {
+		// Hash the left side by key, and probe it with each right element
+		leftIndexes := map[interface{}][]int{}
+		for i, element := range leftElements {
+			k := leftKey(element)
+			leftIndexes[k] = append(leftIndexes[k], i)
+		}
+
+		for j, element := range rightElements {
+			for _, i := range leftIndexes[rightKey(element)] {
+				rightMatches[i] = append(rightMatches[i], j)
+				rightMatched[j] = true
+			}
+		}
+	} else {
+		// Hash the right side by key, and probe it with each left element
+		rightIndexes := map[interface{}][]int{}
+		for j, element := range rightElements {
+			k := rightKey(element)
+			rightIndexes[k] = append(rightIndexes[k], j)
+		}
+
+		for i, element := range leftElements {
+			rightMatches[i] = rightIndexes[leftKey(element)]
+			for _, j := range rightMatches[i] {
+				rightMatched[j] = true
+			}
+		}
+	}
+
+	// Combine in left order
+	joined := []interface{}{}
+	for i, leftElement := range leftElements {
+		if len(rightMatches[i]) == 0 {
+			if joinType != InnerJoin {
+				joined = append(joined, combine(leftElement, nil))
+			}
+
+			continue
+		}
+
+		for _, j := range rightMatches[i] {
+			joined = append(joined, combine(leftElement, rightElements[j]))
+		}
+	}
+
+	// Add unmatched right elements in right order
+	if joinType == OuterJoin {
+		for j, rightElement := range rightElements {
+			if !rightMatched[j] {
+				joined = append(joined, combine(nil, rightElement))
+			}
+		}
+	}
+
+	return joined
+}
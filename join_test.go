@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type joinCustomer struct {
+	id   int
+	name string
+}
+
+type joinOrder struct {
+	id         int
+	customerID int
+}
+
+func TestJoin(t *testing.T) {
+	var (
+		customers = []interface{}{
+			joinCustomer{1, "Alice"},
+			joinCustomer{2, "Bob"},
+			joinCustomer{3, "Carol"},
+		}
+		orders = []interface{}{
+			joinOrder{10, 2},
+			joinOrder{11, 1},
+			joinOrder{12, 2},
+			joinOrder{13, 4},
+		}
+		customerKey = func(element interface{}) interface{} { return element.(joinCustomer).id }
+		orderKey    = func(element interface{}) interface{} { return element.(joinOrder).customerID }
+		combine     = func(l, r interface{}) interface{} {
+			var (
+				name    = "-"
+				orderID = 0
+			)
+			if l != nil {
+				name = l.(joinCustomer).name
+			}
+			if r != nil {
+				orderID = r.(joinOrder).id
+			}
+
+			return KV{name, orderID}
+		}
+	)
+
+	// Empty
+	s := Join(Of(), Of(), customerKey, orderKey, combine)
+	assert.Equal(t, []interface{}{}, s.AndThen().ToSlice())
+
+	// Left is hashed, result is in left order
+	s = Join(Of(customers...), Of(orders...), customerKey, orderKey, combine)
+	assert.Equal(t, []interface{}{KV{"Alice", 11}, KV{"Bob", 10}, KV{"Bob", 12}}, s.AndThen().ToSlice())
+
+	s = Join(Of(customers...), Of(orders...), customerKey, orderKey, combine, LeftJoin)
+	assert.Equal(t, []interface{}{KV{"Alice", 11}, KV{"Bob", 10}, KV{"Bob", 12}, KV{"Carol", 0}}, s.AndThen().ToSlice())
+
+	s = Join(Of(customers...), Of(orders...), customerKey, orderKey, combine, OuterJoin)
+	assert.Equal(
+		t,
+		[]interface{}{KV{"Alice", 11}, KV{"Bob", 10}, KV{"Bob", 12}, KV{"Carol", 0}, KV{"-", 13}},
+		s.AndThen().ToSlice(),
+	)
+
+	// Right is hashed, result is still in left order
+	s = Join(Of(customers...), Of(orders[:2]...), customerKey, orderKey, combine)
+	assert.Equal(t, []interface{}{KV{"Alice", 11}, KV{"Bob", 10}}, s.AndThen().ToSlice())
+
+	s = Join(Of(customers...), Of(orders[:2]...), customerKey, orderKey, combine, OuterJoin)
+	assert.Equal(t, []interface{}{KV{"Alice", 11}, KV{"Bob", 10}, KV{"Carol", 0}}, s.AndThen().ToSlice())
+
+	// Panic on infinite Stream
+	func() {
+		defer func() {
+			assert.Equal(t, ErrInfiniteFinisher, recover())
+		}()
+
+		Join(Of(), Iterate(1, func(element interface{}) interface{} { return element }), customerKey, orderKey, combine)
+		assert.Fail(t, "Must panic")
+	}()
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONArraySink is a Sink that writes elements to an io.Writer as a JSON array as they are consumed, without buffering
+// the array, flushing according to a FlushPolicy.
+// Consume is a consumer suitable for Finisher.ForEach, and Close must be called afterwards to terminate the array.
+// Alternatively, Finisher.ToSink does both.
+// Since a consumer cannot return an error, the first error encountered is retained, all further writes are skipped,
+// and the error is returned by Close and Err.
+//
+// Example usage in an HTTP handler:
+//
+//	if err := stream.AndThen().ToSink(NewJSONArraySink(w, FlushEvery(100))); err != nil { ... }
+type JSONArraySink struct {
+	sw      *sinkWriter
+	started bool
+	closed  bool
+}
+
+// NewJSONArraySink constructs a JSONArraySink that writes to w, flushing according to the given policies
+func NewJSONArraySink(w io.Writer, policies ...FlushPolicy) *JSONArraySink {
+	return &JSONArraySink{sw: newSinkWriter(w, policies)}
+}
+
+// Consume writes the JSON encoding of an element, preceded by "[" for the first element and "," for the rest.
+// Panics if the sink is closed.
+func (s *JSONArraySink) Consume(element interface{}) {
+	if s.closed {
+		panic(ErrSinkClosed)
+	}
+
+	if s.sw.err != nil {
+		return
+	}
+
+	encoded, err := json.Marshal(element)
+	if err != nil {
+		s.sw.err = err
+		return
+	}
+
+	if s.started {
+		s.sw.write([]byte{','})
+	} else {
+		s.started = true
+		s.sw.write([]byte{'['})
+	}
+
+	s.sw.write(encoded)
+	s.sw.elementWritten()
+}
+
+// Close writes "]", preceded by "[" if no elements were consumed, flushes any buffered output, and returns the first
+// error that occurred, if any.
+// Calling Close more than once has no further effect.
+func (s *JSONArraySink) Close() error {
+	if !s.closed {
+		s.closed = true
+
+		if !s.started {
+			s.started = true
+			s.sw.write([]byte{'['})
+		}
+
+		s.sw.write([]byte{']'})
+	}
+
+	return s.sw.flush()
+}
+
+// Err returns the first error that occurred, if any
+func (s *JSONArraySink) Err() error {
+	return s.sw.err
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct {
+	remaining int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.remaining == 0 {
+		return 0, errors.New("write failed")
+	}
+
+	w.remaining--
+	return len(p), nil
+}
+
+func TestJSONArraySink(t *testing.T) {
+	// Empty
+	buf := &bytes.Buffer{}
+	sink := NewJSONArraySink(buf)
+	Of().AndThen().ForEach(sink.Consume)
+	assert.Nil(t, sink.Close())
+	assert.Equal(t, "[]", buf.String())
+
+	// Elements with escaping
+	buf.Reset()
+	sink = NewJSONArraySink(buf)
+	Of(1, "a\"b", map[string]int{"c": 2}).AndThen().ForEach(sink.Consume)
+	assert.Nil(t, sink.Err())
+	assert.Equal(t, `[1,"a\"b",{"c":2}`, buf.String())
+	assert.Nil(t, sink.Close())
+	assert.Nil(t, sink.Close())
+	assert.Equal(t, `[1,"a\"b",{"c":2}]`, buf.String())
+
+	// Consume after Close
+	func() {
+		defer func() {
+			assert.Equal(t, ErrSinkClosed, recover())
+		}()
+
+		sink.Consume(3)
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Encoding error is retained and further elements are skipped
+	buf.Reset()
+	sink = NewJSONArraySink(buf)
+	Of(1, func() {}, 2).AndThen().ForEach(sink.Consume)
+	assert.Error(t, sink.Err())
+	assert.Error(t, sink.Close())
+	assert.Equal(t, "[1", buf.String())
+
+	// Write error
+	sink = NewJSONArraySink(&failingWriter{remaining: 2})
+	Of(1, 2).AndThen().ForEach(sink.Consume)
+	assert.EqualError(t, sink.Close(), "write failed")
+}
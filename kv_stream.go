@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/bantling/goiter"
+)
+
+// KV is a single key/value entry of a KVStream
+type KV struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// KVStream is a Stream of KV entries, which provides transforms and terminals that operate on keys and values separately.
+// It is a thin layer over Stream, so a KVStream can be converted back into a Stream of KV entries at any time.
+type KVStream struct {
+	stream Stream
+}
+
+// ==== Constructors
+
+// OfMap constructs a KVStream of the entries of any map type, such as the result of Finisher.GroupBy.
+// The entries are iterated in the unspecified order of a range over the map.
+// Panics if m is not a map.
+func OfMap(m interface{}) KVStream {
+	val := reflect.ValueOf(m)
+	if val.Kind() != reflect.Map {
+		panic("m must be a map")
+	}
+
+	mapIter := val.MapRange()
+
+	return KVStream{
+		stream: construct(
+			goiter.NewIter(
+				func() (interface{}, bool) {
+					if mapIter.Next() {
+						return KV{Key: mapIter.Key().Interface(), Value: mapIter.Value().Interface()}, true
+					}
+
+					return nil, false
+				},
+			),
+			true,
+		),
+	}
+}
+
+// OfKVs constructs a KVStream of hard-coded entries
+func OfKVs(kvs ...KV) KVStream {
+	return KVStream{
+		stream: construct(
+			goiter.OfElements(kvs),
+			true,
+		),
+	}
+}
+
+// KeyBy returns a KVStream of entries whose value is each element of this Stream, and whose key is the result of f(element)
+func (s Stream) KeyBy(f func(element interface{}) (key interface{})) KVStream {
+	return KVStream{
+		stream: s.Map(
+			func(element interface{}) interface{} {
+				return KV{Key: f(element), Value: element}
+			},
+		),
+	}
+}
+
+// ==== Conversions
+
+// Stream returns a Stream of the KV entries
+func (kvs KVStream) Stream() Stream {
+	return kvs.stream
+}
+
+// Keys returns a Stream of the keys only
+func (kvs KVStream) Keys() Stream {
+	return kvs.stream.Map(
+		func(element interface{}) interface{} {
+			return element.(KV).Key
+		},
+	)
+}
+
+// Values returns a Stream of the values only
+func (kvs KVStream) Values() Stream {
+	return kvs.stream.Map(
+		func(element interface{}) interface{} {
+			return element.(KV).Value
+		},
+	)
+}
+
+// Iter returns an iterator of the KV entries in this KVStream.
+// Note that a KVStream can only be iterated once by a single *goiter.Iter instance.
+func (kvs KVStream) Iter() *goiter.Iter {
+	return kvs.stream.Iter()
+}
+
+// ==== Transforms
+
+// MapKeys maps each key to a new key, possibly of a different type, leaving the value as is
+func (kvs KVStream) MapKeys(f func(key interface{}) interface{}) KVStream {
+	return KVStream{
+		stream: kvs.stream.Map(
+			func(element interface{}) interface{} {
+				kv := element.(KV)
+				return KV{Key: f(kv.Key), Value: kv.Value}
+			},
+		),
+	}
+}
+
+// MapValues maps each value to a new value, possibly of a different type, leaving the key as is
+func (kvs KVStream) MapValues(f func(value interface{}) interface{}) KVStream {
+	return KVStream{
+		stream: kvs.stream.Map(
+			func(element interface{}) interface{} {
+				kv := element.(KV)
+				return KV{Key: kv.Key, Value: f(kv.Value)}
+			},
+		),
+	}
+}
+
+// FilterKeys returns a new KVStream of all entries whose key passes the given predicate
+func (kvs KVStream) FilterKeys(f func(key interface{}) bool) KVStream {
+	return KVStream{
+		stream: kvs.stream.Filter(
+			func(element interface{}) bool {
+				return f(element.(KV).Key)
+			},
+		),
+	}
+}
+
+// SortByKey returns a new KVStream with the entries sorted by key using the provided comparator.
+// Entries with equal keys retain their relative order.
+// All entries are read before the first sorted entry is returned. Since sorting depends on all entries, the KVStream is
+// iterated and sorted sequentially by the parallel methods of Finisher, and only transforms applied after SortByKey are
+// executed in parallel.
+func (kvs KVStream) SortByKey(less func(key1, key2 interface{}) bool) KVStream {
+	return KVStream{
+		stream: kvs.stream.sequentialTransform(
+			func(it *goiter.Iter) *goiter.Iter {
+				var sortedIter *goiter.Iter
+
+				return goiter.NewIter(
+					func() (interface{}, bool) {
+						if sortedIter == nil {
+							// Sort all entries
+							sorted := it.ToSlice()
+							sort.SliceStable(sorted, func(i, j int) bool {
+								return less(sorted[i].(KV).Key, sorted[j].(KV).Key)
+							})
+
+							sortedIter = goiter.OfElements(sorted)
+						}
+
+						// Return next sorted entry
+						if sortedIter.Next() {
+							return sortedIter.Value(), true
+						}
+
+						return nil, false
+					},
+				)
+			},
+			false,
+		),
+	}
+}
+
+// SwapKV returns a new KVStream where the key and value of each entry are swapped
+func (kvs KVStream) SwapKV() KVStream {
+	return KVStream{
+		stream: kvs.stream.Map(
+			func(element interface{}) interface{} {
+				kv := element.(KV)
+				return KV{Key: kv.Value, Value: kv.Key}
+			},
+		),
+	}
+}
+
+// ==== Terminals
+
+// ToMap returns a map of all entries.
+// If multiple entries have the same key, the last value wins.
+// Panics if the KVStream is infinite.
+func (kvs KVStream) ToMap() map[interface{}]interface{} {
+	m := map[interface{}]interface{}{}
+
+	kvs.stream.AndThen().ForEach(
+		func(element interface{}) {
+			kv := element.(KV)
+			m[kv.Key] = kv.Value
+		},
+	)
+
+	return m
+}
+
+// ToMultiMap returns a map of all entries, where each key maps to all of its values in stream order.
+// Panics if the KVStream is infinite.
+func (kvs KVStream) ToMultiMap() map[interface{}][]interface{} {
+	m := map[interface{}][]interface{}{}
+
+	kvs.stream.AndThen().ForEach(
+		func(element interface{}) {
+			kv := element.(KV)
+			m[kv.Key] = append(m[kv.Key], kv.Value)
+		},
+	)
+
+	return m
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/bantling/gofuncs"
+	"github.com/stretchr/testify/assert"
+)
+
+// ==== Constructors
+
+func TestOfMap(t *testing.T) {
+	kvs := OfMap(map[string]int{})
+	assert.Equal(t, map[interface{}]interface{}{}, kvs.ToMap())
+
+	kvs = OfMap(map[string]int{"a": 1, "b": 2})
+	assert.Equal(t, map[interface{}]interface{}{"a": 1, "b": 2}, kvs.ToMap())
+
+	kvs = OfMap(Of(1, 2, 4).AndThen().GroupBy(func(element interface{}) interface{} { return element.(int) % 2 }))
+	assert.Equal(t, map[interface{}]interface{}{0: []interface{}{2, 4}, 1: []interface{}{1}}, kvs.ToMap())
+
+	func() {
+		defer func() {
+			assert.Equal(t, "m must be a map", recover())
+		}()
+
+		OfMap(1)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestOfKVs(t *testing.T) {
+	kvs := OfKVs()
+	assert.Equal(t, []interface{}{}, kvs.Stream().AndThen().ToSlice())
+
+	kvs = OfKVs(KV{1, "a"}, KV{2, "b"})
+	assert.Equal(t, []interface{}{KV{1, "a"}, KV{2, "b"}}, kvs.Stream().AndThen().ToSlice())
+}
+
+func TestStreamKeyBy(t *testing.T) {
+	kvs := Of("a", "bb", "cc").KeyBy(func(element interface{}) interface{} { return len(element.(string)) })
+	assert.Equal(t, []interface{}{KV{1, "a"}, KV{2, "bb"}, KV{2, "cc"}}, kvs.Stream().AndThen().ToSlice())
+}
+
+// ==== Conversions
+
+func TestKVStreamKeysValues(t *testing.T) {
+	kvs := OfKVs(KV{1, "a"}, KV{2, "b"})
+	assert.Equal(t, []int{1, 2}, kvs.Keys().AndThen().ToSliceOf(0))
+
+	kvs = OfKVs(KV{1, "a"}, KV{2, "b"})
+	assert.Equal(t, []string{"a", "b"}, kvs.Values().AndThen().ToSliceOf(""))
+}
+
+func TestKVStreamIter(t *testing.T) {
+	it := OfKVs(KV{1, "a"}).Iter()
+	assert.True(t, it.Next())
+	assert.Equal(t, KV{1, "a"}, it.Value())
+	assert.False(t, it.Next())
+}
+
+// ==== Transforms
+
+func TestKVStreamMapKeys(t *testing.T) {
+	kvs := OfKVs(KV{1, "a"}, KV{2, "b"}).MapKeys(func(key interface{}) interface{} { return strconv.Itoa(key.(int)) })
+	assert.Equal(t, map[interface{}]interface{}{"1": "a", "2": "b"}, kvs.ToMap())
+}
+
+func TestKVStreamMapValues(t *testing.T) {
+	kvs := OfKVs(KV{1, "a"}, KV{2, "b"}).MapValues(func(value interface{}) interface{} { return value.(string) + "!" })
+	assert.Equal(t, map[interface{}]interface{}{1: "a!", 2: "b!"}, kvs.ToMap())
+}
+
+func TestKVStreamFilterKeys(t *testing.T) {
+	kvs := OfKVs(KV{1, "a"}, KV{2, "b"}, KV{3, "c"}).FilterKeys(func(key interface{}) bool { return key.(int) != 2 })
+	assert.Equal(t, map[interface{}]interface{}{1: "a", 3: "c"}, kvs.ToMap())
+}
+
+func TestKVStreamSortByKey(t *testing.T) {
+	kvs := OfKVs().SortByKey(gofuncs.IntSortFunc)
+	assert.Equal(t, []interface{}{}, kvs.Stream().AndThen().ToSlice())
+
+	kvs = OfKVs(KV{3, "c"}, KV{1, "a"}, KV{2, "b"}, KV{1, "d"}).SortByKey(gofuncs.IntSortFunc)
+	assert.Equal(t, []interface{}{KV{1, "a"}, KV{1, "d"}, KV{2, "b"}, KV{3, "c"}}, kvs.Stream().AndThen().ToSlice())
+
+	// All entries are sorted together by the parallel methods
+	kvs = OfKVs(KV{8, "h"}, KV{3, "c"}, KV{6, "f"}, KV{1, "a"}, KV{7, "g"}, KV{2, "b"}, KV{5, "e"}, KV{4, "d"}).
+		SortByKey(gofuncs.IntSortFunc)
+	assert.Equal(
+		t,
+		[]interface{}{1, 2, 3, 4, 5, 6, 7, 8},
+		kvs.Stream().Map(func(element interface{}) interface{} { return element.(KV).Key }).AndThen().ParallelToSlice(2),
+	)
+}
+
+func TestKVStreamSwapKV(t *testing.T) {
+	kvs := OfKVs(KV{1, "a"}, KV{2, "b"}).SwapKV()
+	assert.Equal(t, map[interface{}]interface{}{"a": 1, "b": 2}, kvs.ToMap())
+}
+
+// ==== Terminals
+
+func TestKVStreamToMap(t *testing.T) {
+	kvs := OfKVs(KV{1, "a"}, KV{1, "b"})
+	assert.Equal(t, map[interface{}]interface{}{1: "b"}, kvs.ToMap())
+}
+
+func TestKVStreamToMultiMap(t *testing.T) {
+	kvs := OfKVs()
+	assert.Equal(t, map[interface{}][]interface{}{}, kvs.ToMultiMap())
+
+	kvs = OfKVs(KV{1, "a"}, KV{2, "b"}, KV{1, "c"})
+	assert.Equal(t, map[interface{}][]interface{}{1: {"a", "c"}, 2: {"b"}}, kvs.ToMultiMap())
+}
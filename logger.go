@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"sync/atomic"
+
+	"github.com/bantling/goiter"
+)
+
+// Logger is the interface used by Stream.WithLogger to log pipeline execution, which is satisfied by *log.Logger
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// LogLevel is the level of detail logged by Stream.WithLogger.
+// The level can be changed at any time with Set, including while a pipeline is executing.
+type LogLevel int32
+
+const (
+	// LogOff logs nothing
+	LogOff LogLevel = iota
+	// LogStages logs when iteration of a stage starts, and when it ends along with the number of elements iterated
+	LogStages
+	// LogElements logs the same as LogStages, and also logs elements
+	LogElements
+)
+
+// Get returns the current level
+func (l *LogLevel) Get() LogLevel {
+	return LogLevel(atomic.LoadInt32((*int32)(l)))
+}
+
+// Set changes the current level
+func (l *LogLevel) Set(level LogLevel) {
+	atomic.StoreInt32((*int32)(l), int32(level))
+}
+
+// WithLogger returns a new Stream that logs the elements produced by the transforms applied so far, according to the
+// current value of level each time a message could be logged.
+// If the optional sampleEvery value is provided, only every nth element is logged at LogElements level, counting from the first.
+// Each call adds a new stage to the pipeline, so a separate Logger with a distinct prefix can be used to identify each stage.
+func (s Stream) WithLogger(l Logger, level *LogLevel, sampleEvery ...uint) Stream {
+	every := uint(1)
+	if (len(sampleEvery) > 0) && (sampleEvery[0] > 0) {
+		every = sampleEvery[0]
+	}
+
+	return s.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				started bool
+				count   uint
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !started {
+						started = true
+						if level.Get() >= LogStages {
+							l.Printf("stage started")
+						}
+					}
+
+					if !it.Next() {
+						if level.Get() >= LogStages {
+							l.Printf("stage ended after %d elements", count)
+						}
+
+						return nil, false
+					}
+
+					val := it.Value()
+					if (level.Get() >= LogElements) && (count%every == 0) {
+						l.Printf("element %d: %v", count, val)
+					}
+
+					count++
+					return val, true
+				},
+			)
+		},
+	)
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevel(t *testing.T) {
+	level := LogOff
+	assert.Equal(t, LogOff, level.Get())
+
+	level.Set(LogElements)
+	assert.Equal(t, LogElements, level.Get())
+}
+
+func TestStreamWithLogger(t *testing.T) {
+	var (
+		buf   = &bytes.Buffer{}
+		l     = log.New(buf, "double: ", 0)
+		fn    = func(element interface{}) interface{} { return element.(int) * 2 }
+		level = LogOff
+	)
+
+	// Off
+	s := Of(1, 2).Map(fn).WithLogger(l, &level)
+	assert.Equal(t, []int{2, 4}, s.AndThen().ToSliceOf(0))
+	assert.Equal(t, "", buf.String())
+
+	// Stages
+	level.Set(LogStages)
+	s = Of(1, 2).Map(fn).WithLogger(l, &level)
+	assert.Equal(t, []int{2, 4}, s.AndThen().ToSliceOf(0))
+	assert.Equal(t, "double: stage started\ndouble: stage ended after 2 elements\n", buf.String())
+
+	// Elements
+	buf.Reset()
+	level.Set(LogElements)
+	s = Of(1, 2).Map(fn).WithLogger(l, &level)
+	assert.Equal(t, []int{2, 4}, s.AndThen().ToSliceOf(0))
+	assert.Equal(
+		t,
+		"double: stage started\ndouble: element 0: 2\ndouble: element 1: 4\ndouble: stage ended after 2 elements\n",
+		buf.String(),
+	)
+
+	// Sampled elements
+	buf.Reset()
+	s = Of(1, 2, 3).Map(fn).WithLogger(l, &level, 2)
+	assert.Equal(t, []int{2, 4, 6}, s.AndThen().ToSliceOf(0))
+	assert.Equal(
+		t,
+		"double: stage started\ndouble: element 0: 2\ndouble: element 2: 6\ndouble: stage ended after 3 elements\n",
+		buf.String(),
+	)
+
+	// Level changed during iteration
+	buf.Reset()
+	level.Set(LogStages)
+	it := Of(1, 2).Map(fn).WithLogger(l, &level).Iter()
+	assert.True(t, it.Next())
+	level.Set(LogOff)
+	assert.True(t, it.Next())
+	assert.False(t, it.Next())
+	assert.Equal(t, "double: stage started\n", buf.String())
+}
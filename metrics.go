@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/bantling/goiter"
+)
+
+// MetricsHook receives events as a pipeline executes, for publishing metrics such as element throughput and terminal latency.
+// A hook can be set for all Streams with SetMetricsHook, or for a single Stream with Stream.WithMetrics.
+// Implementations must be safe for concurrent use, as parallel terminals may execute stages on multiple goroutines.
+//
+// To avoid a dependency, no Prometheus adapter is provided - a Prometheus user implements this interface with counters
+// and histograms of their choice. An expvar adapter is provided by ExpvarMetrics.
+type MetricsHook interface {
+	// OnStageStart is called when iteration of a stage starts, where a stage is a named MetricsStage or a terminal method
+	OnStageStart(stage string)
+	// OnElement is called for each element that passes through a MetricsStage
+	OnElement(stage string)
+	// OnTerminalEnd is called when a terminal method ends, with the number of elements it read and how long it took
+	OnTerminalEnd(terminal string, elements uint64, duration time.Duration)
+}
+
+var (
+	globalMetricsHookMutex sync.RWMutex
+	globalMetricsHook      MetricsHook
+)
+
+// SetMetricsHook sets the MetricsHook used by every Stream that has not been given one with Stream.WithMetrics.
+// A nil hook disables metrics, which is the default.
+func SetMetricsHook(h MetricsHook) {
+	globalMetricsHookMutex.Lock()
+	defer globalMetricsHookMutex.Unlock()
+
+	globalMetricsHook = h
+}
+
+// metricsHook returns the hook of the Stream if it has one, else the global hook
+func (s Stream) metricsHook() MetricsHook {
+	if s.metrics != nil {
+		return s.metrics
+	}
+
+	globalMetricsHookMutex.RLock()
+	defer globalMetricsHookMutex.RUnlock()
+
+	return globalMetricsHook
+}
+
+// WithMetrics returns a new Stream that reports to the given hook instead of the global hook.
+// The hook applies to stages added by MetricsStage after this call, and to the terminal method.
+func (s Stream) WithMetrics(h MetricsHook) Stream {
+	s.metrics = h
+	return s
+}
+
+// MetricsStage returns a new Stream that reports the elements produced by the transforms applied so far as a stage of
+// the given name. The hook is resolved when iteration starts.
+func (s Stream) MetricsStage(stage string) Stream {
+	return s.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				started bool
+				hook    MetricsHook
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !started {
+						started = true
+						if hook = s.metricsHook(); hook != nil {
+							hook.OnStageStart(stage)
+						}
+					}
+
+					if !it.Next() {
+						return nil, false
+					}
+
+					if hook != nil {
+						hook.OnElement(stage)
+					}
+
+					return it.Value(), true
+				},
+			)
+		},
+	)
+}
+
+// ExpvarMetrics is a MetricsHook that publishes the following integer counters in an expvar.Map:
+// <stage>.started is the number of times a stage or terminal started;
+// <stage>.elements is the number of elements that passed through a stage;
+// <terminal>.ended is the number of times a terminal ended;
+// <terminal>.elements is the number of elements read by a terminal;
+// <terminal>.nanoseconds is the total time spent in a terminal.
+type ExpvarMetrics struct {
+	m *expvar.Map
+}
+
+// NewExpvarMetrics constructs an ExpvarMetrics that publishes a map of the given name.
+// Panics if the name is already published, as expvar.Publish does.
+func NewExpvarMetrics(name string) ExpvarMetrics {
+	return ExpvarMetrics{m: expvar.NewMap(name)}
+}
+
+// Map returns the published map
+func (e ExpvarMetrics) Map() *expvar.Map {
+	return e.m
+}
+
+// OnStageStart is MetricsHook.OnStageStart
+func (e ExpvarMetrics) OnStageStart(stage string) {
+	e.m.Add(stage+".started", 1)
+}
+
+// OnElement is MetricsHook.OnElement
+func (e ExpvarMetrics) OnElement(stage string) {
+	e.m.Add(stage+".elements", 1)
+}
+
+// OnTerminalEnd is MetricsHook.OnTerminalEnd
+func (e ExpvarMetrics) OnTerminalEnd(terminal string, elements uint64, duration time.Duration) {
+	e.m.Add(terminal+".ended", 1)
+	e.m.Add(terminal+".elements", int64(elements))
+	e.m.Add(terminal+".nanoseconds", int64(duration))
+}
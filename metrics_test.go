@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingMetrics struct {
+	mutex    sync.Mutex
+	events   []string
+	elements map[string]uint64
+}
+
+func (r *recordingMetrics) OnStageStart(stage string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, "start "+stage)
+}
+
+func (r *recordingMetrics) OnElement(stage string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, "element "+stage)
+}
+
+func (r *recordingMetrics) OnTerminalEnd(terminal string, elements uint64, duration time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, "end "+terminal)
+	if r.elements == nil {
+		r.elements = map[string]uint64{}
+	}
+	r.elements[terminal] = elements
+}
+
+func TestStreamWithMetrics(t *testing.T) {
+	fn := func(element interface{}) interface{} { return element.(int) * 2 }
+
+	// Per stream hook
+	r := &recordingMetrics{}
+	s := Of(1, 2).WithMetrics(r).Map(fn).MetricsStage("double")
+	assert.Equal(t, []int{2, 4}, s.AndThen().ToSliceOf(0))
+	assert.Equal(
+		t,
+		[]string{"start ToSliceOf", "start double", "element double", "element double", "end ToSliceOf"},
+		r.events,
+	)
+	assert.Equal(t, map[string]uint64{"ToSliceOf": 2}, r.elements)
+
+	// Terminal with a Finisher filter only counts the elements read by the terminal
+	r = &recordingMetrics{}
+	assert.Equal(t, 1, Of(1, 2, 3).WithMetrics(r).AndThen().Filter(func(element interface{}) bool {
+		return element.(int) == 2
+	}).Count())
+	assert.Equal(t, map[string]uint64{"Count": 1}, r.elements)
+
+	// Parallel terminal
+	r = &recordingMetrics{}
+	assert.Equal(t, []int{2, 4, 6}, Of(1, 2, 3).WithMetrics(r).Map(fn).AndThen().ParallelToSliceOf(0, 2))
+	assert.Equal(t, map[string]uint64{"ParallelToSliceOf": 3}, r.elements)
+
+	// Global hook
+	r = &recordingMetrics{}
+	SetMetricsHook(r)
+	defer SetMetricsHook(nil)
+	assert.Equal(t, 3, Of(1, 2, 3).MetricsStage("s").AndThen().Count())
+	assert.Equal(t, uint64(3), r.elements["Count"])
+	assert.Equal(t, "start Count", r.events[0])
+	assert.Equal(t, "start s", r.events[1])
+
+	// Per stream hook overrides global hook
+	r2 := &recordingMetrics{}
+	assert.Equal(t, 1, Of(1).WithMetrics(r2).AndThen().Count())
+	assert.Equal(t, map[string]uint64{"Count": 1}, r2.elements)
+	assert.Equal(t, uint64(3), r.elements["Count"])
+}
+
+// expvarMetrics is created once, since a name can only be published once per process
+var expvarMetrics = NewExpvarMetrics("gostream_test")
+
+func TestExpvarMetrics(t *testing.T) {
+	e := expvarMetrics
+	e.Map().Init()
+	s := Of(1, 2).WithMetrics(e).MetricsStage("stage")
+	assert.Equal(t, []interface{}{1, 2}, s.AndThen().ToSlice())
+
+	assert.Equal(t, "1", e.Map().Get("stage.started").String())
+	assert.Equal(t, "2", e.Map().Get("stage.elements").String())
+	assert.Equal(t, "1", e.Map().Get("ToSlice.started").String())
+	assert.Equal(t, "1", e.Map().Get("ToSlice.ended").String())
+	assert.Equal(t, "2", e.Map().Get("ToSlice.elements").String())
+	assert.NotNil(t, e.Map().Get("ToSlice.nanoseconds"))
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"github.com/bantling/goiter"
+)
+
+// NilPolicy indicates how a Stream treats nil elements, where nil includes nil pointers, slices, maps, channels, funcs,
+// and interfaces
+type NilPolicy uint
+
+const (
+	// KeepNils is the default, and passes nil elements to transforms and terminals like any other element
+	KeepNils NilPolicy = iota
+	// SkipNils discards nil elements
+	SkipNils
+	// FailOnNil panics with ErrNilElement when a nil element occurs
+	FailOnNil
+)
+
+const (
+	// ErrNilElement is thrown when a nil element occurs in a Stream with the FailOnNil policy
+	ErrNilElement = "A nil element occurred in a Stream with the FailOnNil policy"
+)
+
+// withNilPolicy returns a transform that applies the given policy to the elements produced by the given transform
+func withNilPolicy(policy NilPolicy, t func(*goiter.Iter) *goiter.Iter) func(*goiter.Iter) *goiter.Iter {
+	if policy == KeepNils {
+		return t
+	}
+
+	return func(it *goiter.Iter) *goiter.Iter {
+		tit := t(it)
+
+		return goiter.NewIter(
+			func() (interface{}, bool) {
+				for tit.Next() {
+					val := tit.Value()
+					if !isNil(val) {
+						return val, true
+					}
+
+					if policy == FailOnNil {
+						panic(ErrNilElement)
+					}
+				}
+
+				return nil, false
+			},
+		)
+	}
+}
+
+// WithNilPolicy returns a new Stream that applies the given policy to its elements, and to the elements produced by
+// every transform added afterwards, including Finisher transforms, so that neither transforms nor terminals receive
+// nil elements unless the policy is KeepNils.
+// Transforms added before this call still receive nil elements.
+// Streams constructed from the results of this Stream, such as by Cache, ToStream, or the parallel methods, do not
+// inherit the policy.
+func (s Stream) WithNilPolicy(policy NilPolicy) Stream {
+	s.nilPolicy = policy
+
+	return s.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			return it
+		},
+	)
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamWithNilPolicy(t *testing.T) {
+	var (
+		nilPtr    *int
+		nilIfEven = func(element interface{}) interface{} {
+			if element.(int)%2 == 0 {
+				return nil
+			}
+			return element
+		}
+	)
+
+	// KeepNils
+	assert.Equal(t, []interface{}{1, nil, nilPtr}, Of(1, nil, nilPtr).WithNilPolicy(KeepNils).AndThen().ToSlice())
+
+	// SkipNils applies to the source, later Stream transforms, and later Finisher transforms
+	assert.Equal(t, []interface{}{1}, Of(1, nil, nilPtr).WithNilPolicy(SkipNils).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 3}, Of(1, 2, 3).WithNilPolicy(SkipNils).Map(nilIfEven).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 3}, Of(1, 2, 3).WithNilPolicy(SkipNils).AndThen().ReplaceAll(func(element interface{}) bool { return element == 2 }, nil).ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{1, 3},
+		Of(3, 2, 1).
+			WithNilPolicy(SkipNils).
+			Map(nilIfEven).
+			AndThen().
+			Sorted(func(e1, e2 interface{}) bool { return e1.(int) < e2.(int) }).
+			ToSlice(),
+	)
+
+	// Order dependent transforms
+	assert.Equal(t, []interface{}{1, 3}, Of(1, 2, 3, 4).WithNilPolicy(SkipNils).Map(nilIfEven).Limit(3).AndThen().ToSlice())
+
+	// Transforms before the policy still receive nils
+	var received []interface{}
+	assert.Equal(
+		t,
+		[]interface{}{1},
+		Of(1, nil).Peek(func(element interface{}) { received = append(received, element) }).WithNilPolicy(SkipNils).AndThen().ToSlice(),
+	)
+	assert.Equal(t, []interface{}{1, nil}, received)
+
+	// The policy is kept by OnClose
+	assert.Equal(t, []interface{}{1, 3}, Of(1, 2, 3).WithNilPolicy(SkipNils).OnClose(func() error { return nil }).Map(nilIfEven).AndThen().ToSlice())
+
+	// FailOnNil
+	func() {
+		defer func() {
+			assert.Equal(t, ErrNilElement, recover())
+		}()
+
+		Of(1, 2).WithNilPolicy(FailOnNil).Map(nilIfEven).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	assert.Equal(t, []interface{}{1, 3}, Of(1, 3).WithNilPolicy(FailOnNil).Map(nilIfEven).AndThen().ToSlice())
+}
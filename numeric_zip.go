@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/bantling/goiter"
+)
+
+const (
+	// ErrZipLengths is thrown when the Streams passed to ZipInts, ZipFloats, or ZipNumeric have different lengths
+	ErrZipLengths = "The zipped Streams must have the same length"
+)
+
+// NumericOp is an arithmetic operation for ZipNumeric
+type NumericOp uint
+
+const (
+	// OpAdd adds the elements
+	OpAdd NumericOp = iota
+	// OpSubtract subtracts the second element from the first
+	OpSubtract
+	// OpMultiply multiplies the elements
+	OpMultiply
+	// OpDivide divides the first element by the second, where integers use integer division
+	OpDivide
+)
+
+// toInt64 converts any int or uint kind to an int64.
+// Panics if the element is not an int or uint kind.
+func toInt64(element interface{}) int64 {
+	switch val := reflect.ValueOf(element); val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int()
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(val.Uint())
+	}
+
+	panic(fmt.Sprintf("element %v of type %T is not an integer", element, element))
+}
+
+// toFloat64 converts any int, uint, or float kind to a float64.
+// Panics if the element is not an int, uint, or float kind.
+func toFloat64(element interface{}) float64 {
+	switch val := reflect.ValueOf(element); val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(val.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		return val.Float()
+	}
+
+	panic(fmt.Sprintf("element %v of type %T is not a number", element, element))
+}
+
+// isInteger is true if the element is an int or uint kind
+func isInteger(element interface{}) bool {
+	switch reflect.ValueOf(element).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+
+	return false
+}
+
+// zipPairs returns a Stream of the result of calling f with each pair of elements of s1 and s2.
+// The Stream is finite if either Stream is finite, and closing it closes both Streams.
+// If exactly one Stream is infinite, the Stream ends when the finite Stream is exhausted.
+// Otherwise, the Stream panics with ErrZipLengths if one Stream is exhausted before the other.
+func zipPairs(s1, s2 Stream, f func(e1, e2 interface{}) interface{}) Stream {
+	var it1, it2 *goiter.Iter
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				if it1 == nil {
+					it1, it2 = s1.Iter(), s2.Iter()
+				}
+
+				next1, next2 := it1.Next(), it2.Next()
+				if !(next1 && next2) {
+					// A finite Stream zipped with an infinite Stream ends with the finite Stream
+					if (next1 != next2) && (s1.finite == s2.finite) {
+						panic(ErrZipLengths)
+					}
+
+					return nil, false
+				}
+
+				return f(it1.Value(), it2.Value()), true
+			},
+		),
+		s1.finite || s2.finite,
+	).OnClose(
+		func() error {
+			var errs CloseErrors
+			for _, s := range []Stream{s1, s2} {
+				if err := s.Close(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if len(errs) > 0 {
+				return errs
+			}
+
+			return nil
+		},
+	)
+}
+
+// ZipInts returns a Stream of the result of applying op to each pair of elements of two Streams of the same length,
+// such as for elementwise vector arithmetic. The elements may be any int or uint kind, and are converted to int64.
+// If exactly one Stream is infinite, the Stream ends when the finite Stream is exhausted.
+// The Stream panics during iteration with ErrZipLengths if two finite Streams have different lengths, or if an element
+// is not an integer.
+func ZipInts(op func(a, b int64) int64, s1, s2 Stream) Stream {
+	return zipPairs(
+		s1,
+		s2,
+		func(e1, e2 interface{}) interface{} {
+			return op(toInt64(e1), toInt64(e2))
+		},
+	)
+}
+
+// ZipFloats is like ZipInts, except that the elements may be any int, uint, or float kind, and are converted to float64
+func ZipFloats(op func(a, b float64) float64, s1, s2 Stream) Stream {
+	return zipPairs(
+		s1,
+		s2,
+		func(e1, e2 interface{}) interface{} {
+			return op(toFloat64(e1), toFloat64(e2))
+		},
+	)
+}
+
+// ZipNumeric returns a Stream of the result of applying an arithmetic operation to each pair of elements of two Streams
+// of the same length. If both elements of a pair are any int or uint kind, the result is an int64, otherwise the elements
+// may be any int, uint, or float kind, and the result is a float64.
+// If exactly one Stream is infinite, the Stream ends when the finite Stream is exhausted.
+// The Stream panics during iteration with ErrZipLengths if two finite Streams have different lengths, or if an element
+// is not a number, or an integer is divided by zero.
+func ZipNumeric(op NumericOp, s1, s2 Stream) Stream {
+	return zipPairs(
+		s1,
+		s2,
+		func(e1, e2 interface{}) interface{} {
+			if isInteger(e1) && isInteger(e2) {
+				a, b := toInt64(e1), toInt64(e2)
+
+				switch op {
+				case OpAdd:
+					return a + b
+				case OpSubtract:
+					return a - b
+				case OpMultiply:
+					return a * b
+				default:
+					return a / b
+				}
+			}
+
+			a, b := toFloat64(e1), toFloat64(e2)
+
+			switch op {
+			case OpAdd:
+				return a + b
+			case OpSubtract:
+				return a - b
+			case OpMultiply:
+				return a * b
+			default:
+				return a / b
+			}
+		},
+	)
+}
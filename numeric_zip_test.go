@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZipInts(t *testing.T) {
+	add := func(a, b int64) int64 { return a + b }
+
+	assert.Equal(t, []interface{}{}, ZipInts(add, Of(), Of()).AndThen().ToSlice())
+	assert.Equal(t, []int64{5, 7, 9}, ZipInts(add, Of(1, 2, 3), Of(int8(4), uint(5), int64(6))).AndThen().ToSliceOf(int64(0)))
+
+	func() {
+		defer func() {
+			assert.Equal(t, ErrZipLengths, recover())
+		}()
+
+		ZipInts(add, Of(1, 2), Of(1)).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	func() {
+		defer func() {
+			assert.Equal(t, "element 1.5 of type float64 is not an integer", recover())
+		}()
+
+		ZipInts(add, Of(1), Of(1.5)).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestZipFloats(t *testing.T) {
+	mul := func(a, b float64) float64 { return a * b }
+
+	assert.Equal(t, []float64{1.5, 5, 0.25}, ZipFloats(mul, Of(1, 2.5, float32(0.5)), Of(1.5, uint8(2), 0.5)).AndThen().ToSliceOf(0.0))
+
+	func() {
+		defer func() {
+			assert.Equal(t, "element a of type string is not a number", recover())
+		}()
+
+		ZipFloats(mul, Of(1), Of("a")).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestZipNumeric(t *testing.T) {
+	var (
+		ints   = func() Stream { return Of(7, 8) }
+		others = func() Stream { return Of(2, 2.5) }
+	)
+
+	assert.Equal(t, []interface{}{int64(9), 10.5}, ZipNumeric(OpAdd, ints(), others()).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{int64(5), 5.5}, ZipNumeric(OpSubtract, ints(), others()).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{int64(14), 20.0}, ZipNumeric(OpMultiply, ints(), others()).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{int64(3), 3.2}, ZipNumeric(OpDivide, ints(), others()).AndThen().ToSlice())
+
+	// Infinite streams
+	inc := func(element interface{}) interface{} { return element.(int) + 1 }
+	assert.Equal(t, []interface{}{int64(2), int64(4)}, ZipNumeric(OpAdd, Iterate(0, inc), Iterate(0, inc)).Limit(2).AndThen().ToSlice())
+
+	// A finite stream zipped with an infinite stream ends with the finite stream
+	assert.Equal(t, []interface{}{int64(8), int64(10)}, ZipNumeric(OpAdd, ints(), Iterate(0, inc)).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{int64(9), int64(11)}, ZipNumeric(OpAdd, Iterate(1, inc), ints()).AndThen().ToSlice())
+
+	// Closing closes both streams
+	var closed []string
+	s := ZipNumeric(
+		OpAdd,
+		Of(1).OnClose(func() error { closed = append(closed, "s1"); return errors.New("s1") }),
+		Of(1).OnClose(func() error { closed = append(closed, "s2"); return nil }),
+	)
+	assert.Equal(t, []interface{}{int64(2)}, s.AndThen().ToSlice())
+	assert.Equal(t, []string{"s1", "s2"}, closed)
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+// OrderedMap is a map that iterates its keys in the order they were first put, such as the result of
+// Finisher.ToOrderedMap, for consumers that need deterministic output.
+// The zero value is not usable, use NewOrderedMap.
+type OrderedMap struct {
+	keys   []interface{}
+	values map[interface{}]interface{}
+}
+
+// NewOrderedMap constructs an empty OrderedMap
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{values: map[interface{}]interface{}{}}
+}
+
+// Get returns the value of a key, and true if the key exists
+func (m *OrderedMap) Get(key interface{}) (value interface{}, ok bool) {
+	value, ok = m.values[key]
+	return value, ok
+}
+
+// Has returns true if the key exists
+func (m *OrderedMap) Has(key interface{}) bool {
+	_, ok := m.values[key]
+	return ok
+}
+
+// Put sets the value of a key. A new key is added after the existing keys, and an existing key keeps its position.
+func (m *OrderedMap) Put(key, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = value
+}
+
+// Len returns the number of keys
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns a copy of the keys in order
+func (m *OrderedMap) Keys() []interface{} {
+	return append([]interface{}{}, m.keys...)
+}
+
+// KVs returns a KVStream of the entries in key order
+func (m *OrderedMap) KVs() KVStream {
+	kvs := make([]KV, len(m.keys))
+	for i, k := range m.keys {
+		kvs[i] = KV{Key: k, Value: m.values[k]}
+	}
+
+	return OfKVs(kvs...)
+}
+
+// ToOrderedMap is like ToMap, except that it returns an OrderedMap whose keys are in the order they first occur in.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ToOrderedMap(f func(interface{}) (key interface{}, value interface{})) *OrderedMap {
+	term := fin.terminal("ToOrderedMap")
+	defer term.done()
+
+	m := NewOrderedMap()
+
+	for it := term.Iter(); it.Next(); {
+		m.Put(f(it.Value()))
+	}
+
+	return m
+}
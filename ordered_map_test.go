@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedMap(t *testing.T) {
+	m := NewOrderedMap()
+	assert.Equal(t, 0, m.Len())
+	assert.False(t, m.Has("a"))
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+
+	m.Put("b", 1)
+	m.Put("a", 2)
+	m.Put("b", 3)
+	assert.Equal(t, 2, m.Len())
+	assert.True(t, m.Has("a"))
+	v, ok := m.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, []interface{}{"b", "a"}, m.Keys())
+	assert.Equal(t, []interface{}{KV{"b", 3}, KV{"a", 2}}, m.KVs().Stream().AndThen().ToSlice())
+
+	// Keys is a copy
+	m.Keys()[0] = "c"
+	assert.Equal(t, []interface{}{"b", "a"}, m.Keys())
+}
+
+func TestStreamToOrderedMap(t *testing.T) {
+	fn := func(element interface{}) (interface{}, interface{}) {
+		return element, strconv.Itoa(element.(int))
+	}
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().ToOrderedMap(fn).Keys())
+
+	m := Of(3, 1, 2, 1).AndThen().ToOrderedMap(fn)
+	assert.Equal(t, []interface{}{3, 1, 2}, m.Keys())
+	assert.Equal(t, map[interface{}]interface{}{1: "1", 2: "2", 3: "3"}, m.KVs().ToMap())
+}
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"runtime"
+
+	"github.com/bantling/goiter"
+)
+
+// ParallelOptions configures Finisher.ParallelWithOptions, which uses WorkStealing.
+// The zero value uses runtime.GOMAXPROCS goroutines that claim DefaultNumberOfParallelItems items at a time.
+type ParallelOptions struct {
+	// Workers is the number of goroutines, or runtime.GOMAXPROCS if it is not positive
+	Workers int
+	// ChunkSize is the number of items each goroutine claims at a time, or DefaultNumberOfParallelItems if it is not positive
+	ChunkSize int
+	// WorkerInit is optional, and is called by each goroutine before it processes any items, to create state that the
+	// goroutine owns for its lifetime, such as a database connection, API client, or buffer.
+	// If it fails, no further items are claimed, and ParallelWithOptions returns the first error.
+	WorkerInit func() (state interface{}, err error)
+	// WorkerClose is optional, and is called by each goroutine whose WorkerInit succeeded with the state it returned,
+	// after the goroutine has processed its items
+	WorkerClose func(state interface{})
+	// Map is optional, and is called by each goroutine with its state and each item, after the transforms of the Stream.
+	// The state is nil if there is no WorkerInit.
+	Map func(state, element interface{}) interface{}
+}
+
+// ParallelWithOptions processes the result of the current Finisher in parallel as configured by the options, and returns
+// a new Stream that iterates the ordered results.
+// Returns the first error from ParallelOptions.WorkerInit, if any.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ParallelWithOptions(opts ParallelOptions) (s Stream, err error) {
+	term := fin.terminal("ParallelWithOptions")
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	fin.panicIfInfinite()
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = int(DefaultNumberOfParallelItems)
+	}
+
+	data, err := doParallelWorkers(
+		fin.source.source().ToSlice(),
+		workers,
+		chunkSize,
+		func() (func(*goiter.Iter) *goiter.Iter, func(), error) {
+			var state interface{}
+			if opts.WorkerInit != nil {
+				var err error
+				if state, err = opts.WorkerInit(); err != nil {
+					return nil, nil, err
+				}
+			}
+
+			// Apply the Stream transforms, then the Map of this worker
+			transform := compose(
+				fin.source.transform,
+				func(it *goiter.Iter) *goiter.Iter {
+					if opts.Map == nil {
+						return it
+					}
+
+					return goiter.NewIter(
+						func() (interface{}, bool) {
+							if it.Next() {
+								return opts.Map(state, it.Value()), true
+							}
+
+							return nil, false
+						},
+					)
+				},
+			)
+
+			var done func()
+			if opts.WorkerClose != nil {
+				done = func() { opts.WorkerClose(state) }
+			}
+
+			return transform, done, nil
+		},
+	)
+	if err != nil {
+		return s, err
+	}
+
+	// If the finisher is non-nil, apply it afterwards - it cannot be done in parallel
+	if fin.transform != nil {
+		data = fin.transform(goiter.Of(data...)).ToSlice()
+	}
+	term.elements = uint64(len(data))
+
+	s = Of(data...)
+	return s, err
+}
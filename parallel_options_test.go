@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamParallelWithOptions(t *testing.T) {
+	var (
+		input    []interface{}
+		expected []interface{}
+		double   = func(element interface{}) interface{} { return element.(int) * 2 }
+	)
+
+	for i := 0; i < 100; i++ {
+		input = append(input, i)
+		expected = append(expected, i*2+1)
+	}
+
+	// Zero value
+	s, err := Of().AndThen().ParallelWithOptions(ParallelOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{}, s.AndThen().ToSlice())
+
+	s, err = Of(3, 1, 3).Map(double).AndThen().Distinct().ParallelWithOptions(ParallelOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{6, 2}, s.AndThen().ToSlice())
+
+	// Worker state
+	var (
+		mutex  sync.Mutex
+		inits  int
+		closed []interface{}
+		opts   = ParallelOptions{
+			Workers:   4,
+			ChunkSize: 3,
+			WorkerInit: func() (interface{}, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				inits++
+				return 1, nil
+			},
+			WorkerClose: func(state interface{}) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				closed = append(closed, state)
+			},
+			Map: func(state, element interface{}) interface{} {
+				return element.(int) + state.(int)
+			},
+		}
+	)
+
+	s, err = Of(input...).Map(double).AndThen().ParallelWithOptions(opts)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, s.AndThen().ToSlice())
+	assert.Equal(t, 4, inits)
+	assert.Equal(t, []interface{}{1, 1, 1, 1}, closed)
+
+	// Map without state
+	s, err = Of(1, 2).AndThen().ParallelWithOptions(ParallelOptions{
+		Map: func(state, element interface{}) interface{} {
+			assert.Nil(t, state)
+			return element
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{1, 2}, s.AndThen().ToSlice())
+
+	// Init error
+	closed = nil
+	opts.WorkerInit = func() (interface{}, error) { return nil, errors.New("no connection") }
+	_, err = Of(input...).AndThen().ParallelWithOptions(opts)
+	assert.EqualError(t, err, "no connection")
+	assert.Nil(t, closed)
+}
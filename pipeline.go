@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/bantling/goiter"
+)
+
+// EmitOrder indicates the order in which Finisher.ParallelPipeline emits results
+type EmitOrder uint
+
+const (
+	// SourceOrder is the default, and emits results in the order of the source elements
+	SourceOrder EmitOrder = iota
+	// ArrivalOrder emits results as soon as any goroutine produces them, trading order for latency
+	ArrivalOrder
+)
+
+// pipelineJob is a source element to be transformed by a goroutine of ParallelPipeline
+type pipelineJob struct {
+	index   uint64
+	element interface{}
+}
+
+// pipelineResult is the result of transforming a source element, or a panic from reading or transforming it
+type pipelineResult struct {
+	index    uint64
+	values   []interface{}
+	panicked bool
+	panicVal interface{}
+}
+
+// ParallelPipeline returns a Stream that applies the Stream transforms to elements with a number of goroutines while the
+// results are being iterated, rather than collecting all results first like the other parallel methods, so it can be
+// used for infinite Streams and the first results are available as soon as possible.
+// Each element is transformed separately, so a transform that operates across elements, such as MapBatched, sees one
+// element at a time. The Finisher transforms are applied sequentially to the results.
+//
+// In SourceOrder, the results are in the order of the source elements.
+// In ArrivalOrder, each result is emitted as soon as any goroutine produces it.
+// In both cases, at most window elements are read from the source but not yet emitted, which bounds memory, and in
+// ArrivalOrder also bounds how far ahead of an earlier element a result can be emitted. A window of 1 processes one element
+// at a time. If window is not provided, it is twice the number of goroutines.
+//
+// If workers < 1, runtime.GOMAXPROCS goroutines are used.
+// No goroutines are started until the first element is read. A panic while reading the source or in a transform is
+// rethrown when the consumer reaches the result of the element that caused it.
+// Closing the result stops the goroutines, and closes this Finisher.
+func (fin Finisher) ParallelPipeline(workers int, order EmitOrder, window ...uint) Stream {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	w := uint(2 * workers)
+	if (len(window) > 0) && (window[0] > 0) {
+		w = window[0]
+	}
+
+	var (
+		stop      = make(chan struct{})
+		stopOnce  sync.Once
+		slots     = make(chan struct{}, w)
+		results   = make(chan pipelineResult, w)
+		pending   = map[uint64]pipelineResult{}
+		nextIndex uint64
+		values    []interface{}
+		started   bool
+	)
+
+	// transform applies the Stream transforms to one element
+	transform := func(job pipelineJob) (result pipelineResult) {
+		result.index = job.index
+
+		defer func() {
+			if err := recover(); err != nil {
+				result.panicked, result.panicVal = true, err
+			}
+		}()
+
+		if fin.source.transform == nil {
+			result.values = []interface{}{job.element}
+		} else {
+			result.values = fin.source.transform(goiter.Of(job.element)).ToSlice()
+		}
+
+		return result
+	}
+
+	// start starts the goroutine that reads the source, and the goroutines that transform elements
+	start := func() {
+		var (
+			source     = fin.source.source()
+			jobs       = make(chan pipelineJob)
+			sourceDone = make(chan struct{})
+			wg         = &sync.WaitGroup{}
+		)
+
+		go func() {
+			defer close(sourceDone)
+			defer close(jobs)
+
+			var index uint64
+			defer func() {
+				if err := recover(); err != nil {
+					select {
+					case results <- pipelineResult{index: index, panicked: true, panicVal: err}:
+					case <-stop:
+					}
+				}
+			}()
+
+			for ; ; index++ {
+				// Wait for room in the window
+				select {
+				case slots <- struct{}{}:
+				case <-stop:
+					return
+				}
+
+				if !source.Next() {
+					return
+				}
+
+				select {
+				case jobs <- pipelineJob{index: index, element: source.Value()}:
+				case <-stop:
+					return
+				}
+			}
+		}()
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for job := range jobs {
+					select {
+					case results <- transform(job):
+					case <-stop:
+						return
+					}
+				}
+			}()
+		}
+
+		// Close results once the source is exhausted and all elements are transformed
+		go func() {
+			<-sourceDone
+			wg.Wait()
+			close(results)
+		}()
+	}
+
+	// emit returns the values of a result, rethrowing any panic, and makes room in the window
+	emit := func(result pipelineResult) []interface{} {
+		<-slots
+
+		if result.panicked {
+			panic(result.panicVal)
+		}
+
+		return result.values
+	}
+
+	it := goiter.NewIter(
+		func() (interface{}, bool) {
+			if !started {
+				started = true
+				start()
+			}
+
+			for len(values) == 0 {
+				if order == SourceOrder {
+					if result, ok := pending[nextIndex]; ok {
+						delete(pending, nextIndex)
+						nextIndex++
+						values = emit(result)
+						continue
+					}
+				}
+
+				result, ok := <-results
+				if !ok {
+					return nil, false
+				}
+
+				if order == SourceOrder {
+					pending[result.index] = result
+				} else {
+					values = emit(result)
+				}
+			}
+
+			val := values[0]
+			values = values[1:]
+
+			return val, true
+		},
+	)
+
+	if fin.transform != nil {
+		it = fin.transform(it)
+	}
+
+	result := construct(it, fin.finite)
+	result.metrics = fin.source.metrics
+
+	return result.OnClose(
+		func() error {
+			stopOnce.Do(func() { close(stop) })
+			return fin.Close()
+		},
+	)
+}
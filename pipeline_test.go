@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelPipeline(t *testing.T) {
+	// Elements are transformed out of order, but emitted in source order
+	sleepMap := func(element interface{}) interface{} {
+		time.Sleep(time.Duration(11-element.(int)) * time.Millisecond)
+		return element.(int) * 2
+	}
+
+	assert.Equal(
+		t,
+		[]int{2, 4, 6, 8, 10, 12, 14, 16, 18, 20},
+		Iterate(0, func(element interface{}) interface{} { return element.(int) + 1 }).
+			Limit(10).
+			Map(sleepMap).
+			AndThen().
+			ParallelPipeline(4, SourceOrder).
+			AndThen().
+			ToSliceOf(0),
+	)
+
+	// Filtered elements produce no results
+	assert.Equal(
+		t,
+		[]int{2, 4},
+		Of(1, 2, 3, 4, 5).
+			Filter(func(element interface{}) bool { return element.(int)%2 == 0 }).
+			AndThen().
+			ParallelPipeline(2, SourceOrder).
+			AndThen().
+			ToSliceOf(0),
+	)
+
+	// Finisher transforms are applied to the results
+	assert.Equal(
+		t,
+		[]int{6, 4, 2},
+		Of(1, 2, 3).
+			Map(func(element interface{}) interface{} { return element.(int) * 2 }).
+			AndThen().
+			ReverseSorted(func(e1, e2 interface{}) bool { return e1.(int) < e2.(int) }).
+			ParallelPipeline(0, SourceOrder).
+			AndThen().
+			ToSliceOf(0),
+	)
+
+	// Empty
+	assert.Equal(t, []interface{}{}, Of().AndThen().ParallelPipeline(2, ArrivalOrder).AndThen().ToSlice())
+}
+
+func TestParallelPipelineArrivalOrder(t *testing.T) {
+	slowFirst := func(element interface{}) interface{} {
+		if element.(int) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return element
+	}
+
+	// The slow first element is emitted last
+	assert.Equal(
+		t,
+		1,
+		Of(1, 2, 3).Map(slowFirst).AndThen().ParallelPipeline(3, ArrivalOrder).AndThen().ToSlice()[2],
+	)
+
+	// A window of 1 processes one element at a time, so results are in source order
+	assert.Equal(
+		t,
+		[]int{1, 2, 3},
+		Of(1, 2, 3).Map(slowFirst).AndThen().ParallelPipeline(3, ArrivalOrder, 1).AndThen().ToSliceOf(0),
+	)
+}
+
+func TestParallelPipelineInfinite(t *testing.T) {
+	var closed bool
+	s := Iterate(0, func(element interface{}) interface{} { return element.(int) + 1 }).
+		OnClose(func() error { closed = true; return nil }).
+		Map(func(element interface{}) interface{} { return element.(int) * 10 }).
+		AndThen().
+		ParallelPipeline(4, SourceOrder)
+
+	assert.Equal(t, []int{10, 20, 30}, s.Limit(3).AndThen().ToSliceOf(0))
+	assert.True(t, closed)
+}
+
+func TestParallelPipelinePanic(t *testing.T) {
+	var (
+		results []interface{}
+		s       = Of(1, 2, 3).
+			Map(func(element interface{}) interface{} {
+				if element.(int) == 2 {
+					panic("bad element")
+				}
+				return element
+			}).
+			AndThen().
+			ParallelPipeline(2, SourceOrder)
+	)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "bad element", recover())
+		}()
+
+		for it := s.Iter(); it.Next(); {
+			results = append(results, it.Value())
+		}
+		assert.Fail(t, "Must panic")
+	}()
+
+	// The element before the panic is emitted
+	assert.Equal(t, []interface{}{1}, results)
+	assert.Nil(t, s.Close())
+}
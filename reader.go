@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"io"
+
+	"github.com/bantling/goiter"
+)
+
+// ErrReaderClosed is returned by Read after the io.ReadCloser returned by Finisher.Reader is closed
+var ErrReaderClosed = errors.New("gostream: Reader is closed")
+
+// finisherReader is the io.ReadCloser returned by Finisher.Reader
+type finisherReader struct {
+	term   *terminal
+	it     *goiter.Iter
+	encode func(element interface{}) []byte
+	buf    []byte
+	err    error
+	closed bool
+}
+
+// Reader returns an io.ReadCloser that serves the bytes of each element as encoded by the given function, so the
+// Finisher can feed any API that reads from an io.Reader without buffering the encoded result.
+// Elements are only read as needed to fill the slice passed to Read, and the encoding of an element can be any length.
+// When the elements are exhausted, the Stream is closed, and Read returns any error from closing instead of io.EOF.
+// If reading stops before io.EOF, such as when the consumer fails, Close must be called to close the Stream.
+// Panics if the Finisher is infinite.
+func (fin Finisher) Reader(encode func(element interface{}) []byte) io.ReadCloser {
+	term := fin.terminal("Reader")
+
+	return &finisherReader{
+		term:   term,
+		it:     term.Iter(),
+		encode: encode,
+	}
+}
+
+// Read is io.Reader.Read
+func (r *finisherReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		if !r.it.Next() {
+			if r.err = r.term.done(); r.err == nil {
+				r.err = io.EOF
+			}
+
+			return 0, r.err
+		}
+
+		r.buf = r.encode(r.it.Value())
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+// Close is io.Closer.Close.
+// If the elements are not exhausted, the Stream is closed, and any error from closing is returned.
+// Read returns ErrReaderClosed after Close. Calling Close more than once has no further effect.
+func (r *finisherReader) Close() error {
+	if r.closed {
+		return nil
+	}
+
+	var (
+		// The Stream is already closed if Read has reached the end of the elements
+		finished = r.err != nil
+		err      error
+	)
+
+	if !finished {
+		err = r.term.done()
+	}
+
+	r.closed, r.buf, r.err = true, nil, ErrReaderClosed
+	return err
+}
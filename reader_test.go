@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamReader(t *testing.T) {
+	encode := func(element interface{}) []byte {
+		return []byte(strconv.Itoa(element.(int)) + "\n")
+	}
+
+	// Empty
+	b, err := ioutil.ReadAll(Of().AndThen().Reader(encode))
+	assert.Nil(t, err)
+	assert.Equal(t, "", string(b))
+
+	// Elements are read lazily, and encodings are split across reads
+	var read []int
+	r := Of(1, 22, 333).Peek(func(element interface{}) { read = append(read, element.(int)) }).AndThen().Reader(encode)
+	assert.Nil(t, read)
+
+	buf := make([]byte, 3)
+	n, err := r.Read(buf)
+	assert.Equal(t, 2, n)
+	assert.Nil(t, err)
+	assert.Equal(t, "1\n", string(buf[:n]))
+	assert.Equal(t, []int{1}, read)
+
+	n, _ = r.Read(buf[:0])
+	assert.Equal(t, 0, n)
+
+	b, err = ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "22\n333\n", string(b))
+
+	n, err = r.Read(buf)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, io.EOF, err)
+
+	// Empty encodings are skipped
+	b, _ = ioutil.ReadAll(Of(1, 2).AndThen().Reader(func(element interface{}) []byte {
+		if element == 1 {
+			return nil
+		}
+		return []byte("x")
+	}))
+	assert.Equal(t, "x", string(b))
+
+	// Close error
+	_, err = ioutil.ReadAll(Of(1).OnClose(func() error { return errors.New("close") }).AndThen().Reader(encode))
+	assert.EqualError(t, err, "close")
+
+	// Closing after the end has no further effect
+	r = Of(1).AndThen().Reader(encode)
+	_, err = ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Close())
+	_, err = r.Read(buf)
+	assert.Equal(t, ErrReaderClosed, err)
+
+	// Closing early closes the Stream, and returns the close error
+	closed := 0
+	r = Of(1, 2).OnClose(func() error { closed++; return errors.New("close") }).AndThen().Reader(encode)
+	n, err = r.Read(buf)
+	assert.Equal(t, 2, n)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, closed)
+
+	assert.EqualError(t, r.Close(), "close")
+	assert.Equal(t, 1, closed)
+	assert.Nil(t, r.Close())
+	assert.Equal(t, 1, closed)
+
+	n, err = r.Read(buf)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, ErrReaderClosed, err)
+
+	// Infinite
+	func() {
+		defer func() {
+			assert.Equal(t, ErrInfiniteFinisher, recover())
+		}()
+
+		Iterate(1, func(element interface{}) interface{} { return element }).AndThen().Reader(encode)
+		assert.Fail(t, "Must panic")
+	}()
+}
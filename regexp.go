@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/bantling/goiter"
+)
+
+// ofRegexp is the implementation of OfRegexpMatches and OfRegexpSubmatches.
+// The input is read a line at a time, and find returns the elements for each line.
+func ofRegexp(r io.Reader, find func(line string) []interface{}) Stream {
+	var (
+		br        = bufio.NewReader(r)
+		pending   []interface{}
+		exhausted bool
+	)
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				for (len(pending) == 0) && (!exhausted) {
+					line, err := br.ReadString('\n')
+					if err == io.EOF {
+						exhausted = true
+						if line == "" {
+							break
+						}
+					} else if err != nil {
+						panic(err)
+					}
+
+					pending = find(strings.TrimSuffix(line, "\n"))
+				}
+
+				if len(pending) == 0 {
+					return nil, false
+				}
+
+				val := pending[0]
+				pending = pending[1:]
+
+				return val, true
+			},
+		),
+		true,
+	)
+}
+
+// OfRegexpMatches constructs a stream of the strings that match the regexp, read from r a line at a time, so that
+// arbitrarily large input can be searched with memory bounded by the longest line.
+// Since each line is searched separately without its trailing newline, matches cannot span lines, and ^ and $ match at
+// the start and end of each line.
+// The stream panics with an error during iteration if r cannot be read.
+func OfRegexpMatches(r io.Reader, re *regexp.Regexp) Stream {
+	return ofRegexp(
+		r,
+		func(line string) []interface{} {
+			matches := re.FindAllString(line, -1)
+
+			result := make([]interface{}, len(matches))
+			for i, match := range matches {
+				result[i] = match
+			}
+
+			return result
+		},
+	)
+}
+
+// OfRegexpSubmatches is the same as OfRegexpMatches, except that each element is a []string of the match and its
+// capture groups, as returned by regexp.FindAllStringSubmatch.
+func OfRegexpSubmatches(r io.Reader, re *regexp.Regexp) Stream {
+	return ofRegexp(
+		r,
+		func(line string) []interface{} {
+			matches := re.FindAllStringSubmatch(line, -1)
+
+			result := make([]interface{}, len(matches))
+			for i, match := range matches {
+				result[i] = match
+			}
+
+			return result
+		},
+	)
+}
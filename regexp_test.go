@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type errorReader struct{}
+
+func (errorReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestOfRegexpMatches(t *testing.T) {
+	re := regexp.MustCompile(`[a-z]+=(\d+)`)
+
+	assert.Equal(t, []interface{}{}, OfRegexpMatches(strings.NewReader(""), re).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{}, OfRegexpMatches(strings.NewReader("none\n\n"), re).AndThen().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{"a=1", "bc=23", "d=4"},
+		OfRegexpMatches(strings.NewReader("a=1 x bc=23\n\nd=4"), re).AndThen().ToSlice(),
+	)
+
+	// Anchors match each line
+	assert.Equal(
+		t,
+		[]interface{}{"a", "c"},
+		OfRegexpMatches(strings.NewReader("a b\nc d\n"), regexp.MustCompile(`^\w`)).AndThen().ToSlice(),
+	)
+
+	// Long input is read incrementally
+	long := io.MultiReader(strings.NewReader(strings.Repeat("x=1\n", 10000)), errorReader{})
+	it := OfRegexpMatches(long, re).Iter()
+	assert.True(t, it.Next())
+	assert.Equal(t, "x=1", it.Value())
+
+	func() {
+		defer func() {
+			assert.EqualError(t, recover().(error), "read failed")
+		}()
+
+		OfRegexpMatches(errorReader{}, re).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestOfRegexpSubmatches(t *testing.T) {
+	re := regexp.MustCompile(`([a-z]+)=(\d+)`)
+
+	assert.Equal(t, []interface{}{}, OfRegexpSubmatches(strings.NewReader(""), re).AndThen().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{[]string{"a=1", "a", "1"}, []string{"bc=23", "bc", "23"}},
+		OfRegexpSubmatches(strings.NewReader("a=1\nbc=23\n"), re).AndThen().ToSlice(),
+	)
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	// ErrDuplicateRoute is thrown when a Router is given two routes with the same name
+	ErrDuplicateRoute = "duplicate route %s"
+)
+
+// route is a named predicate and the handler of the elements that match it
+type route struct {
+	name    string
+	match   func(element interface{}) bool
+	handler func(Stream)
+}
+
+// Router is a set of named routes used by Finisher.Route to split the elements of a Stream into separate
+// sub-pipelines in one pass, such as valid and invalid records, or one file per region.
+// Routes are tried in the order they are added, and each element is dispatched to the first route whose predicate
+// matches, else to the Otherwise handler if there is one, else it is dropped.
+type Router struct {
+	routes    []route
+	otherwise func(Stream)
+}
+
+// NewRouter constructs a Router with no routes
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Route adds a route that sends the elements that match a predicate to a handler, and returns the Router for chaining.
+// Panics if a route of the same name has already been added.
+func (r *Router) Route(name string, match func(element interface{}) bool, handler func(Stream)) *Router {
+	for _, rt := range r.routes {
+		if rt.name == name {
+			panic(fmt.Sprintf(ErrDuplicateRoute, name))
+		}
+	}
+
+	r.routes = append(r.routes, route{name: name, match: match, handler: handler})
+	return r
+}
+
+// Otherwise sets the handler of the elements that do not match any route, and returns the Router for chaining
+func (r *Router) Otherwise(handler func(Stream)) *Router {
+	r.otherwise = handler
+	return r
+}
+
+// Route classifies each element once with the routes of the given Router, and dispatches it to the Stream of the
+// matching handler. Each handler is called in a separate goroutine with a Stream of the elements routed to it, which
+// it should consume, and the elements are sent over a channel with the given buffer size, which is 0 if not provided.
+// Any elements a handler does not consume are discarded once it returns, so one handler cannot block the others.
+// Returns after all handlers have returned, with an error describing the first route, in the order added, whose
+// handler panicked, else any error from closing the Stream.
+// Panics if the Finisher is infinite.
+func (fin Finisher) Route(r *Router, buffer ...int) (err error) {
+	fin.panicIfInfinite()
+
+	var (
+		size     int
+		handlers = make([]func(Stream), 0, len(r.routes)+1)
+		names    = make([]string, 0, len(r.routes)+1)
+	)
+
+	if len(buffer) > 0 {
+		size = buffer[0]
+	}
+
+	for _, rt := range r.routes {
+		handlers = append(handlers, rt.handler)
+		names = append(names, "route "+rt.name)
+	}
+
+	if r.otherwise != nil {
+		handlers = append(handlers, r.otherwise)
+		names = append(names, "otherwise")
+	}
+
+	var (
+		term = fin.terminal("Route")
+		chs  = make([]chan interface{}, len(handlers))
+		errs = make([]error, len(handlers))
+		wg   sync.WaitGroup
+	)
+
+	for i, handler := range handlers {
+		ch := make(chan interface{}, size)
+		chs[i] = ch
+
+		wg.Add(1)
+		go func(handler func(Stream), handlerErr *error) {
+			defer func() {
+				// Discard anything the handler did not consume
+				for {
+					if _, ok := <-ch; !ok {
+						break
+					}
+				}
+
+				wg.Done()
+			}()
+			defer recoverError(handlerErr)
+
+			handler(MergeChans(ch))
+		}(handler, &errs[i])
+	}
+
+	defer func() {
+		for _, ch := range chs {
+			close(ch)
+		}
+
+		wg.Wait()
+
+		for i, handlerErr := range errs {
+			if handlerErr != nil {
+				err = fmt.Errorf("%s: %w", names[i], handlerErr)
+				break
+			}
+		}
+
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	for it := term.Iter(); it.Next(); {
+		val := it.Value()
+
+		index := -1
+		for i, rt := range r.routes {
+			if rt.match(val) {
+				index = i
+				break
+			}
+		}
+
+		if index == -1 {
+			if r.otherwise == nil {
+				continue
+			}
+
+			index = len(r.routes)
+		}
+
+		chs[index] <- val
+	}
+
+	return err
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinisherRoute(t *testing.T) {
+	var (
+		mutex   sync.Mutex
+		results = map[string][]int{}
+		collect = func(name string) func(Stream) {
+			return func(s Stream) {
+				values := s.AndThen().ToSliceOf(0).([]int)
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				results[name] = values
+			}
+		}
+		negative = func(element interface{}) bool { return element.(int) < 0 }
+		even     = func(element interface{}) bool { return element.(int)%2 == 0 }
+		calls    int
+		counted  = func(element interface{}) bool {
+			calls++
+			return even(element)
+		}
+	)
+
+	// Each element goes to the first matching route only, in order
+	r := NewRouter().
+		Route("negative", negative, collect("negative")).
+		Route("even", counted, collect("even")).
+		Otherwise(collect("other"))
+	assert.Nil(t, Of(1, -2, 2, 3, -5, 4, 6).AndThen().Route(r))
+	assert.Equal(
+		t,
+		map[string][]int{"negative": {-2, -5}, "even": {2, 4, 6}, "other": {1, 3}},
+		results,
+	)
+	assert.Equal(t, 5, calls)
+
+	// Unmatched elements are dropped without Otherwise, and a route with no elements gets an empty Stream
+	results = map[string][]int{}
+	r = NewRouter().
+		Route("negative", negative, collect("negative")).
+		Route("even", even, collect("even"))
+	assert.Nil(t, Of(1, 2, 3).AndThen().Route(r, 1))
+	assert.Equal(t, map[string][]int{"negative": {}, "even": {2}}, results)
+
+	// A handler that stops early does not block the others
+	results = map[string][]int{}
+	r = NewRouter().
+		Route("first", even, func(s Stream) {
+			first := s.AndThen().FindFirst().MustGet().(int)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			results["first"] = []int{first}
+		}).
+		Otherwise(collect("other"))
+	assert.Nil(t, Of(1, 2, 3, 4, 5, 6).AndThen().Route(r))
+	assert.Equal(t, map[string][]int{"first": {2}, "other": {1, 3, 5}}, results)
+
+	// Handler panic is returned as an error naming the route
+	r = NewRouter().
+		Route("even", even, func(s Stream) {
+			s.AndThen().ForEach(func(element interface{}) {
+				panic(fmt.Errorf("bad %d", element))
+			})
+		}).
+		Otherwise(func(Stream) { panic("other") })
+	err := Of(1, 2, 3).AndThen().Route(r)
+	assert.Equal(t, "route even: bad 2", err.Error())
+	assert.Equal(t, "bad 2", errors.Unwrap(err).Error())
+
+	err = Of(1).AndThen().Route(NewRouter().Otherwise(func(Stream) { panic("other") }))
+	assert.Equal(t, "otherwise: other", err.Error())
+
+	// Close error
+	closeErr := errors.New("close")
+	assert.Equal(t, CloseErrors{closeErr}, Of(1).OnClose(func() error { return closeErr }).AndThen().Route(NewRouter()))
+
+	func() {
+		defer func() {
+			assert.Equal(t, fmt.Sprintf(ErrDuplicateRoute, "a"), recover())
+		}()
+
+		NewRouter().Route("a", even, collect("a")).Route("a", even, collect("a"))
+		assert.Fail(t, "Must panic")
+	}()
+}
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// ErrSinkClosed is thrown when an element is consumed by a Sink that is closed
+	ErrSinkClosed = "The Sink is closed"
+)
+
+// Sink consumes elements, typically by writing them somewhere, and must be closed after the last element.
+// Since a consumer cannot return an error, a Sink retains the first error that occurs, skips all further elements,
+// and returns the error from Close.
+type Sink interface {
+	// Consume consumes an element
+	Consume(element interface{})
+	// Close completes the output after the last element, and returns the first error that occurred, if any
+	Close() error
+}
+
+// FlushPolicy determines when a Sink that buffers its output flushes it.
+// A sink flushes whenever any of the policies passed to it are due, and always flushes when closed.
+// If no policies are passed, a sink flushes after every element.
+// If the underlying writer is an http.Flusher, such as an http.ResponseWriter, it is flushed too, so that each flush
+// sends a chunk to the client.
+type FlushPolicy struct {
+	every    uint
+	interval time.Duration
+}
+
+// FlushEvery is a FlushPolicy that flushes after every n elements
+func FlushEvery(n uint) FlushPolicy {
+	return FlushPolicy{every: n}
+}
+
+// FlushInterval is a FlushPolicy that flushes after an element is written if at least d has passed since the last flush.
+// A sink has no background goroutine, so nothing is flushed while waiting for the next element.
+func FlushInterval(d time.Duration) FlushPolicy {
+	return FlushPolicy{interval: d}
+}
+
+// sinkWriter is a buffered writer that flushes according to a set of policies, and retains the first error
+type sinkWriter struct {
+	w         *bufio.Writer
+	flusher   http.Flusher
+	every     uint
+	interval  time.Duration
+	count     uint
+	lastFlush time.Time
+	err       error
+}
+
+// newSinkWriter constructs a sinkWriter that writes to w according to the given policies
+func newSinkWriter(w io.Writer, policies []FlushPolicy) *sinkWriter {
+	sw := &sinkWriter{
+		w:         bufio.NewWriter(w),
+		lastFlush: time.Now(),
+	}
+
+	sw.flusher, _ = w.(http.Flusher)
+
+	for _, policy := range policies {
+		if policy.every > 0 {
+			sw.every = policy.every
+		}
+
+		if policy.interval > 0 {
+			sw.interval = policy.interval
+		}
+	}
+
+	if (sw.every == 0) && (sw.interval == 0) {
+		sw.every = 1
+	}
+
+	return sw
+}
+
+// write writes the given bytes if no error has occurred yet
+func (sw *sinkWriter) write(p []byte) {
+	if sw.err == nil {
+		_, sw.err = sw.w.Write(p)
+	}
+}
+
+// Write is io.Writer.Write, for wrapping a sinkWriter in another writer such as a csv.Writer
+func (sw *sinkWriter) Write(p []byte) (int, error) {
+	if sw.write(p); sw.err != nil {
+		return 0, sw.err
+	}
+
+	return len(p), nil
+}
+
+// elementWritten is called after each element is written, and flushes if any policy is due
+func (sw *sinkWriter) elementWritten() {
+	sw.count++
+
+	if ((sw.every > 0) && (sw.count >= sw.every)) || ((sw.interval > 0) && (time.Since(sw.lastFlush) >= sw.interval)) {
+		sw.flush()
+	}
+}
+
+// flush flushes the buffer if no error has occurred yet, and returns the first error that occurred, if any
+func (sw *sinkWriter) flush() error {
+	sw.count = 0
+	sw.lastFlush = time.Now()
+
+	if sw.err == nil {
+		if sw.err = sw.w.Flush(); (sw.err == nil) && (sw.flusher != nil) {
+			sw.flusher.Flush()
+		}
+	}
+
+	return sw.err
+}
+
+// WriterSink is a Sink that writes the encoding of each element to an io.Writer, flushing according to a FlushPolicy
+type WriterSink struct {
+	sw     *sinkWriter
+	encode func(element interface{}) ([]byte, error)
+	closed bool
+}
+
+// WriteSink constructs a WriterSink that writes elements to w as encoded by the given function, flushing according to
+// the given policies
+func WriteSink(w io.Writer, encode func(element interface{}) ([]byte, error), policies ...FlushPolicy) *WriterSink {
+	return &WriterSink{
+		sw:     newSinkWriter(w, policies),
+		encode: encode,
+	}
+}
+
+// Consume writes the encoding of an element.
+// Panics if the sink is closed.
+func (s *WriterSink) Consume(element interface{}) {
+	if s.closed {
+		panic(ErrSinkClosed)
+	}
+
+	if s.sw.err != nil {
+		return
+	}
+
+	var encoded []byte
+	if encoded, s.sw.err = s.encode(element); s.sw.err != nil {
+		return
+	}
+
+	s.sw.write(encoded)
+	s.sw.elementWritten()
+}
+
+// Close flushes any buffered output, and returns the first error that occurred, if any.
+// The io.Writer is not closed. Calling Close more than once has no further effect.
+func (s *WriterSink) Close() error {
+	s.closed = true
+	return s.sw.flush()
+}
+
+// Err returns the first error that occurred, if any
+func (s *WriterSink) Err() error {
+	return s.sw.err
+}
+
+// ToSink passes each element to a Sink, then closes the Sink and returns the first error that occurred in the Sink,
+// or closing the Stream.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ToSink(s Sink) (err error) {
+	term := fin.terminal("ToSink")
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	for it := term.Iter(); it.Next(); {
+		s.Consume(it.Value())
+	}
+
+	return s.Close()
+}
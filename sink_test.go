@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSink(t *testing.T) {
+	var (
+		buf    = &bytes.Buffer{}
+		encode = func(element interface{}) ([]byte, error) {
+			if element == 0 {
+				return nil, errors.New("zero")
+			}
+
+			return []byte(strconv.Itoa(element.(int))), nil
+		}
+	)
+
+	// Default flushes after every element
+	sink := WriteSink(buf, encode)
+	sink.Consume(1)
+	assert.Equal(t, "1", buf.String())
+	sink.Consume(2)
+	assert.Equal(t, "12", buf.String())
+	assert.Nil(t, sink.Close())
+	assert.Nil(t, sink.Close())
+
+	// Consume after Close
+	func() {
+		defer func() {
+			assert.Equal(t, ErrSinkClosed, recover())
+		}()
+
+		sink.Consume(3)
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Flush every n elements
+	buf.Reset()
+	sink = WriteSink(buf, encode, FlushEvery(2))
+	sink.Consume(1)
+	assert.Equal(t, "", buf.String())
+	sink.Consume(2)
+	assert.Equal(t, "12", buf.String())
+	sink.Consume(3)
+	assert.Equal(t, "12", buf.String())
+	assert.Nil(t, sink.Close())
+	assert.Equal(t, "123", buf.String())
+
+	// Flush interval
+	buf.Reset()
+	sink = WriteSink(buf, encode, FlushInterval(time.Hour))
+	sink.Consume(1)
+	assert.Equal(t, "", buf.String())
+
+	buf.Reset()
+	sink = WriteSink(buf, encode, FlushInterval(time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	sink.Consume(1)
+	assert.Equal(t, "1", buf.String())
+
+	// Either policy
+	buf.Reset()
+	sink = WriteSink(buf, encode, FlushInterval(time.Hour), FlushEvery(1))
+	sink.Consume(1)
+	assert.Equal(t, "1", buf.String())
+
+	// Encoding error skips further elements
+	buf.Reset()
+	sink = WriteSink(buf, encode)
+	sink.Consume(1)
+	sink.Consume(0)
+	sink.Consume(2)
+	assert.EqualError(t, sink.Err(), "zero")
+	assert.EqualError(t, sink.Close(), "zero")
+	assert.Equal(t, "1", buf.String())
+
+	// Write error
+	sink = WriteSink(&failingWriter{}, encode)
+	sink.Consume(1)
+	assert.EqualError(t, sink.Close(), "write failed")
+}
+
+func TestStreamToSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.Nil(t, Of(1, "a").AndThen().ToSink(NewJSONArraySink(buf, FlushEvery(10))))
+	assert.Equal(t, `[1,"a"]`, buf.String())
+
+	// Sink error
+	assert.EqualError(t, Of(1).AndThen().ToSink(NewJSONArraySink(&failingWriter{})), "write failed")
+
+	// Close error
+	buf.Reset()
+	assert.EqualError(
+		t,
+		Of(1).OnClose(func() error { return errors.New("close") }).AndThen().ToSink(NewJSONArraySink(buf)),
+		"close",
+	)
+	assert.Equal(t, "[1]", buf.String())
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// sqlBatchInsert executes one multi-row insert of the given rows in a transaction
+func sqlBatchInsert(ctx context.Context, db *sql.DB, insertSQL string, rows [][]interface{}) (err error) {
+	var (
+		query     strings.Builder
+		allArgs   []interface{}
+		tx, txErr = db.BeginTx(ctx, nil)
+	)
+	if txErr != nil {
+		return txErr
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query.WriteString(insertSQL)
+	for i, row := range rows {
+		if i > 0 {
+			query.WriteString(",")
+		}
+
+		query.WriteString(" (")
+		for j := range row {
+			if j > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString("?")
+		}
+		query.WriteString(")")
+
+		allArgs = append(allArgs, row...)
+	}
+
+	if _, err = tx.ExecContext(ctx, query.String(), allArgs...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ToSQLBatch inserts the elements into a database in batches of batchSize elements, where each batch is a single
+// multi-row insert executed in its own transaction.
+// The insertSQL is the statement up to and including the VALUES keyword, such as "INSERT INTO t (a, b) VALUES", and
+// args returns the column values of an element. A row of ? placeholders is appended for each element, so the driver
+// must accept ? placeholders.
+// Returns the first error beginning a transaction, inserting a batch, committing, or closing the Stream, in which case
+// the failed batch is rolled back and no further elements are read. Batches inserted before the error remain committed.
+// Panics if batchSize < 1, or if the Finisher is infinite.
+func (fin Finisher) ToSQLBatch(
+	ctx context.Context,
+	db *sql.DB,
+	insertSQL string,
+	args func(element interface{}) []interface{},
+	batchSize int,
+) (err error) {
+	if batchSize < 1 {
+		panic("batchSize must be > 0")
+	}
+
+	term := fin.terminal("ToSQLBatch")
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	rows := make([][]interface{}, 0, batchSize)
+	for it := term.Iter(); it.Next(); {
+		if rows = append(rows, args(it.Value())); len(rows) == batchSize {
+			if err = sqlBatchInsert(ctx, db, insertSQL, rows); err != nil {
+				return err
+			}
+
+			rows = rows[:0]
+		}
+	}
+
+	if len(rows) > 0 {
+		err = sqlBatchInsert(ctx, db, insertSQL, rows)
+	}
+
+	return err
+}
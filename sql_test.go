@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingDriver is a database driver that records the statements it executes and transaction outcomes
+type recordingDriver struct {
+	events  []string
+	failArg interface{}
+}
+
+var testDriver = &recordingDriver{}
+
+func init() {
+	sql.Register("gostreamtest", testDriver)
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) { return d, nil }
+func (d *recordingDriver) Close() error                          { return nil }
+func (d *recordingDriver) Begin() (driver.Tx, error)             { return d, nil }
+func (d *recordingDriver) Commit() error                         { d.events = append(d.events, "commit"); return nil }
+func (d *recordingDriver) Rollback() error                       { d.events = append(d.events, "rollback"); return nil }
+func (d *recordingDriver) NumInput() int                         { return -1 }
+
+func (d *recordingDriver) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{d, query}, nil
+}
+
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.events = append(s.d.events, fmt.Sprint(s.query, args))
+	for _, arg := range args {
+		if arg == s.d.failArg {
+			return nil, errors.New("insert failed")
+		}
+	}
+
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestToSQLBatch(t *testing.T) {
+	db, err := sql.Open("gostreamtest", "")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	var (
+		ctx  = context.Background()
+		args = func(element interface{}) []interface{} {
+			return []interface{}{int64(element.(int)), fmt.Sprint("v", element)}
+		}
+	)
+
+	// Batches of 2, where the last batch is shorter
+	testDriver.events = nil
+	assert.Nil(t, Of(1, 2, 3).AndThen().ToSQLBatch(ctx, db, "INSERT INTO t (a, b) VALUES", args, 2))
+	assert.Equal(
+		t,
+		[]string{
+			"INSERT INTO t (a, b) VALUES (?, ?), (?, ?)[1 v1 2 v2]",
+			"commit",
+			"INSERT INTO t (a, b) VALUES (?, ?)[3 v3]",
+			"commit",
+		},
+		testDriver.events,
+	)
+
+	// Empty
+	testDriver.events = nil
+	assert.Nil(t, Of().AndThen().ToSQLBatch(ctx, db, "INSERT INTO t (a, b) VALUES", args, 2))
+	assert.Nil(t, testDriver.events)
+
+	// A failed batch is rolled back, and no further elements are read
+	testDriver.events, testDriver.failArg = nil, int64(3)
+	defer func() { testDriver.failArg = nil }()
+	assert.EqualError(t, Of(1, 2, 3, 4, 5).AndThen().ToSQLBatch(ctx, db, "INSERT INTO t (a, b) VALUES", args, 2), "insert failed")
+	assert.Equal(
+		t,
+		[]string{
+			"INSERT INTO t (a, b) VALUES (?, ?), (?, ?)[1 v1 2 v2]",
+			"commit",
+			"INSERT INTO t (a, b) VALUES (?, ?), (?, ?)[3 v3 4 v4]",
+			"rollback",
+		},
+		testDriver.events,
+	)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "batchSize must be > 0", recover())
+		}()
+
+		Of().AndThen().ToSQLBatch(ctx, db, "", args, 0)
+		assert.Fail(t, "Must panic")
+	}()
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"github.com/bantling/goiter"
+)
+
+// StateStore stores the state of stateful operations such as Distinct, DistinctBy, GroupByInto, and ReduceByKeyInto,
+// so that the state can be kept somewhere other than process memory, such as an on-disk key value store, an LRU cache,
+// or a remote cache.
+// Since a StateStore is used while iterating a Stream, an implementation that encounters an error should panic with it,
+// the same as a Stream that reads from an external source.
+type StateStore interface {
+	// Get returns the value stored for a key, and true if the key is stored
+	Get(key interface{}) (value interface{}, ok bool)
+	// Put stores a value for a key, replacing any existing value
+	Put(key, value interface{})
+	// Has returns true if the key is stored
+	Has(key interface{}) bool
+	// Keys returns an iterator of the stored keys, in any order
+	Keys() *goiter.Iter
+}
+
+// MapStateStore is a StateStore that stores state in a map, which is the default for operations that accept a StateStore
+type MapStateStore map[interface{}]interface{}
+
+// Get is StateStore.Get
+func (m MapStateStore) Get(key interface{}) (interface{}, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// Put is StateStore.Put
+func (m MapStateStore) Put(key, value interface{}) {
+	m[key] = value
+}
+
+// Has is StateStore.Has
+func (m MapStateStore) Has(key interface{}) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// Keys is StateStore.Keys
+func (m MapStateStore) Keys() *goiter.Iter {
+	keys := make([]interface{}, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return goiter.OfElements(keys)
+}
+
+// stateStore returns the first store given, else a new MapStateStore.
+// Panics if more than one store is given.
+func stateStore(store []StateStore) StateStore {
+	checkStateStore(store)
+
+	if len(store) == 0 {
+		return MapStateStore{}
+	}
+
+	return store[0]
+}
+
+// checkStateStore panics if more than one store is given, for operations that create the store when iterated
+func checkStateStore(store []StateStore) {
+	if len(store) > 1 {
+		panic("at most one StateStore can be provided")
+	}
+}
+
+// DistinctBy returns a Finisher of the elements whose key has not occurred in a previous element, where the key is the
+// result of the given function.
+// The keys read are stored in the given StateStore, or in a new MapStateStore for each iteration if none is given.
+// A given StateStore keeps the keys read across iterations, so a second iteration of a replayable Stream only returns
+// elements whose key was not read by the first.
+// Panics if more than one StateStore is given.
+func (fin Finisher) DistinctBy(key func(element interface{}) interface{}, store ...StateStore) Finisher {
+	checkStateStore(store)
+
+	return fin.filterEach(
+		func() func(element interface{}) bool {
+			alreadyRead := stateStore(store)
+
+			return func(element interface{}) bool {
+				k := key(element)
+				if !alreadyRead.Has(k) {
+					alreadyRead.Put(k, true)
+					return true
+				}
+
+				return false
+			}
+		},
+	)
+}
+
+// GroupByInto is like GroupBy, except that each key and slice of elements are stored in the given StateStore, so the
+// groups do not have to fit in memory.
+// Panics if the Finisher is infinite.
+func (fin Finisher) GroupByInto(store StateStore, f func(element interface{}) (key interface{})) {
+	fin.aggregateByKeyInto(
+		"GroupByInto",
+		store,
+		f,
+		func() interface{} {
+			return []interface{}(nil)
+		},
+		func(accumulator interface{}, element interface{}) interface{} {
+			return append(accumulator.([]interface{}), element)
+		},
+	)
+}
+
+// ReduceByKeyInto is like ReduceByKey, except that each key and accumulated value are stored in the given StateStore,
+// so the results do not have to fit in memory.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ReduceByKeyInto(
+	store StateStore,
+	key func(element interface{}) interface{},
+	identity interface{},
+	f func(accumulator interface{}, element interface{}) interface{},
+) {
+	fin.aggregateByKeyInto(
+		"ReduceByKeyInto",
+		store,
+		key,
+		func() interface{} {
+			return identity
+		},
+		f,
+	)
+}
+
+// aggregateByKeyInto is AggregateByKey with a StateStore
+func (fin Finisher) aggregateByKeyInto(
+	name string,
+	store StateStore,
+	key func(element interface{}) interface{},
+	identity func() interface{},
+	f func(accumulator interface{}, element interface{}) interface{},
+) {
+	term := fin.terminal(name)
+	defer term.done()
+
+	for it := term.Iter(); it.Next(); {
+		var (
+			element     = it.Value()
+			k           = key(element)
+			accumulator interface{}
+			haveKey     bool
+		)
+
+		if accumulator, haveKey = store.Get(k); !haveKey {
+			accumulator = identity()
+		}
+
+		store.Put(k, f(accumulator, element))
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingStore is a StateStore that counts the number of Put calls
+type countingStore struct {
+	MapStateStore
+	puts int
+}
+
+func (c *countingStore) Put(key, value interface{}) {
+	c.puts++
+	c.MapStateStore.Put(key, value)
+}
+
+func TestMapStateStore(t *testing.T) {
+	m := MapStateStore{}
+	assert.False(t, m.Has(1))
+	_, ok := m.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, []interface{}{}, m.Keys().ToSlice())
+
+	m.Put(1, "one")
+	m.Put(2, "two")
+	assert.True(t, m.Has(1))
+	v, ok := m.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "one", v)
+
+	keys := m.Keys().ToSlice()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].(int) < keys[j].(int) })
+	assert.Equal(t, []interface{}{1, 2}, keys)
+}
+
+func TestStreamDistinctWithStore(t *testing.T) {
+	store := &countingStore{MapStateStore: MapStateStore{}}
+	assert.Equal(t, []interface{}{1, 2}, Of(1, 2, 2, 1).AndThen().Distinct(store).ToSlice())
+	assert.Equal(t, 2, store.puts)
+
+	// Each iteration of a replayable Stream starts with a new MapStateStore, while a given StateStore is kept
+	fin := Of(1, 2, 1).Cache().AndThen().Distinct()
+	assert.Equal(t, []interface{}{1, 2}, fin.ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, fin.ToSlice())
+
+	fin = Of(1, 2, 1).Cache().AndThen().Distinct(MapStateStore{})
+	assert.Equal(t, []interface{}{1, 2}, fin.ToSlice())
+	assert.Equal(t, []interface{}{}, fin.ToSlice())
+
+	func() {
+		defer func() {
+			assert.Equal(t, "at most one StateStore can be provided", recover())
+		}()
+
+		Of().AndThen().Distinct(MapStateStore{}, MapStateStore{})
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamDistinctBy(t *testing.T) {
+	length := func(element interface{}) interface{} { return len(element.(string)) }
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().DistinctBy(length).ToSlice())
+	assert.Equal(t, []interface{}{"a", "bb"}, Of("a", "b", "bb", "cc", "d").AndThen().DistinctBy(length).ToSlice())
+
+	store := MapStateStore{}
+	assert.Equal(t, []interface{}{"a", "bb"}, Of("a", "b", "bb").AndThen().DistinctBy(length, store).ToSlice())
+	assert.Equal(t, MapStateStore{1: true, 2: true}, store)
+
+	// Each iteration of a replayable Stream starts with a new MapStateStore, while a given StateStore is kept
+	fin := Of("a", "b", "bb").Cache().AndThen().DistinctBy(length)
+	assert.Equal(t, []interface{}{"a", "bb"}, fin.ToSlice())
+	assert.Equal(t, []interface{}{"a", "bb"}, fin.ToSlice())
+
+	fin = Of("a", "b", "bb").Cache().AndThen().DistinctBy(length, MapStateStore{})
+	assert.Equal(t, []interface{}{"a", "bb"}, fin.ToSlice())
+	assert.Equal(t, []interface{}{}, fin.ToSlice())
+}
+
+func TestStreamGroupByInto(t *testing.T) {
+	store := MapStateStore{}
+	Of(1, 2, 3, 4, 5).AndThen().GroupByInto(store, func(element interface{}) interface{} { return element.(int) % 2 })
+	assert.Equal(t, MapStateStore{0: []interface{}{2, 4}, 1: []interface{}{1, 3, 5}}, store)
+
+	store = MapStateStore{}
+	Of().AndThen().GroupByInto(store, func(element interface{}) interface{} { return element })
+	assert.Equal(t, MapStateStore{}, store)
+}
+
+func TestStreamReduceByKeyInto(t *testing.T) {
+	store := MapStateStore{}
+	Of(1, 2, 3, 4, 5).AndThen().ReduceByKeyInto(
+		store,
+		func(element interface{}) interface{} { return element.(int) % 2 },
+		0,
+		func(accumulator, element interface{}) interface{} { return accumulator.(int) + element.(int) },
+	)
+	assert.Equal(t, MapStateStore{0: 6, 1: 9}, store)
+}
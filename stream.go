@@ -3,9 +3,16 @@
 package gostream
 
 import (
+	"fmt"
+	"math/big"
+	"math/rand"
 	"reflect"
+	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bantling/goiter"
 	"github.com/bantling/gooptional"
@@ -19,6 +26,32 @@ const (
 	NumberOfGoroutines ParallelFlags = iota
 	// NumberOfItemsPerGoroutine indicates the number of items each goroutine processes
 	NumberOfItemsPerGoroutine
+	// WorkStealing indicates the number of items each goroutine claims at a time from a shared queue, where the number of
+	// goroutines is runtime.GOMAXPROCS. Goroutines that finish early claim more items, so that a few slow items do not
+	// stall the other goroutines.
+	WorkStealing
+)
+
+// OverflowPolicy indicates what Finisher.Buffer does when the buffer is full
+type OverflowPolicy uint
+
+const (
+	// BlockOnOverflow is the default, and blocks reading the source until the consumer makes room in the buffer
+	BlockOnOverflow OverflowPolicy = iota
+	// DropNewest discards an element read from the source when the buffer is full
+	DropNewest
+	// DropOldest discards the oldest element in the buffer to make room for an element read from the source
+	DropOldest
+)
+
+// FilterMode indicates how FilterMatching combines multiple predicates
+type FilterMode uint
+
+const (
+	// MatchAll is the default, and passes elements that pass all predicates
+	MatchAll FilterMode = iota
+	// MatchAny passes elements that pass any predicate
+	MatchAny
 )
 
 const (
@@ -26,30 +59,36 @@ const (
 	DefaultNumberOfParallelItems uint = 50
 )
 
+const (
+	// parallelSampleSize is the number of elements Parallel transforms sequentially to estimate the cost of each element
+	parallelSampleSize = 16
+	// parallelMinDuration is the estimated time to transform the remaining elements below which Parallel transforms them
+	// sequentially, since the overhead of goroutines would outweigh the benefit
+	parallelMinDuration = time.Millisecond
+	// parallelChunkDuration is the estimated time for Parallel to transform the number of items claimed at a time
+	parallelChunkDuration = 100 * time.Microsecond
+)
+
+const (
+	// ErrStreamConsumed is the format of the message thrown when a Stream is iterated after it has already been consumed.
+	// The placeholders are the function that consumed the Stream and where it was called from.
+	ErrStreamConsumed = "The Stream has already been consumed by %s at %s"
+	// ErrCacheOverflow is thrown when a cached Stream is iterated after it read more elements than the maximum allowed
+	ErrCacheOverflow = "The cached Stream read more elements than the maximum allowed, and cannot be iterated again"
+	// ErrMapTimeout is the format of the message thrown when MapWithTimeout has no onTimeout function and an element times out.
+	// The placeholders are the element and the timeout.
+	ErrMapTimeout = "MapWithTimeout: mapping element %v did not complete within %s"
+)
+
 // IterateFunc adapts any func that accepts and returns the exact same type into func(interface{}) interface{} suitable for the Iterate method.
 // Panics if f is not a func that accepts and returns one type that is exactly the same.
 func IterateFunc(f interface{}) func(interface{}) interface{} {
-	var (
-		val = reflect.ValueOf(f)
-		typ = val.Type()
-	)
-
-	if typ.Kind() != reflect.Func {
-		panic("f must be a function")
-	}
-
-	if (typ.NumIn() != 1) || (typ.NumOut() != 1) {
-		panic("f must accept and return a single value of the exact same type")
-	}
-
-	argType, retType := typ.In(0), typ.Out(0)
-	if argType != retType {
-		panic("f must accept and return a single value of the exact same type")
+	fn, err := TryIterateFunc(f)
+	if err != nil {
+		panic(err.Error())
 	}
 
-	return func(arg interface{}) interface{} {
-		return val.Call([]reflect.Value{reflect.ValueOf(arg)})[0].Interface()
-	}
+	return fn
 }
 
 // compose two func(Iter) Iter f1, f2 and returns a composition func(x Iter) Iter of f2(f1(x))
@@ -70,6 +109,23 @@ func compose(f1, f2 func(*goiter.Iter) *goiter.Iter) func(*goiter.Iter) *goiter.
 	return composition
 }
 
+// splitParallel splits the source into rows, one for each goroutine to process
+func splitParallel(source *goiter.Iter, numItems uint, flag ParallelFlags) [][]interface{} {
+	n := DefaultNumberOfParallelItems
+	if numItems > 0 {
+		n = numItems
+	}
+
+	if flag == NumberOfGoroutines {
+		// numItems = desired number of rows; number of colums to be determined
+		return source.SplitIntoColumns(n)
+	}
+
+	// numItems = desired number of columns; number of rows to be determined.
+	// When splitting for WorkStealing, each row is a chunk that can be claimed.
+	return source.SplitIntoRows(n)
+}
+
 // doParallel does the grunt work of parallel processing, returning a slice of results.
 // If numItems is 0, the default value is DefaultNumberOfParallelItems.
 func doParallel(
@@ -79,25 +135,20 @@ func doParallel(
 	numItems uint,
 	flag ParallelFlags,
 ) []interface{} {
-	n := DefaultNumberOfParallelItems
-	if numItems > 0 {
-		n = numItems
-	}
-
 	var flatData []interface{}
 	if transform == nil {
 		// If the transform is nil, there is no transform, just use source vales as is
 		flatData = source.ToSlice()
-	} else {
-		var splitData [][]interface{}
-		if flag == NumberOfGoroutines {
-			// numItems = desired number of rows; number of colums to be determined
-			splitData = source.SplitIntoColumns(n)
-		} else {
-			// numItems = desired number of columns; number of rows to be determined
-			splitData = source.SplitIntoRows(n)
+	} else if flag == WorkStealing {
+		chunkSize := DefaultNumberOfParallelItems
+		if numItems > 0 {
+			chunkSize = numItems
 		}
 
+		flatData = doParallelChunks(source.ToSlice(), transform, runtime.GOMAXPROCS(0), int(chunkSize))
+	} else {
+		splitData := splitParallel(source, numItems, flag)
+
 		// Execute goroutines, one per row of splitData.
 		// Each goroutine applies the queued operations to each item in its row.
 		wg := &sync.WaitGroup{}
@@ -128,6 +179,144 @@ func doParallel(
 	return flatData
 }
 
+// doParallelChunks transforms elements with a number of goroutines that each claim chunkSize elements at a time until
+// there are none left, returning the results in order
+func doParallelChunks(
+	elements []interface{},
+	transform func(*goiter.Iter) *goiter.Iter,
+	workers int,
+	chunkSize int,
+) []interface{} {
+	flatData, _ := doParallelWorkers(
+		elements,
+		workers,
+		chunkSize,
+		func() (func(*goiter.Iter) *goiter.Iter, func(), error) {
+			return transform, nil, nil
+		},
+	)
+
+	return flatData
+}
+
+// doParallelWorkers is the same as doParallelChunks, except that each goroutine first calls newWorker to get the transform
+// it applies and an optional function to call when it is done.
+// If newWorker fails, the goroutine stops, the other goroutines stop claiming chunks, and the first error is returned.
+func doParallelWorkers(
+	elements []interface{},
+	workers int,
+	chunkSize int,
+	newWorker func() (transform func(*goiter.Iter) *goiter.Iter, done func(), err error),
+) ([]interface{}, error) {
+	var (
+		numChunks = (len(elements) + chunkSize - 1) / chunkSize
+		results   = make([][]interface{}, numChunks)
+		nextChunk = int64(-1)
+		wg        = &sync.WaitGroup{}
+		errOnce   sync.Once
+		firstErr  error
+		failed    int32
+	)
+
+	for i := 0; (i < workers) && (i < numChunks); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			transform, done, err := newWorker()
+			if err != nil {
+				errOnce.Do(func() { firstErr = err })
+				atomic.StoreInt32(&failed, 1)
+				return
+			}
+
+			if done != nil {
+				defer done()
+			}
+
+			// Claim chunks until there are none left
+			for atomic.LoadInt32(&failed) == 0 {
+				chunk := int(atomic.AddInt64(&nextChunk, 1))
+				if chunk >= numChunks {
+					return
+				}
+
+				var (
+					low  = chunk * chunkSize
+					high = low + chunkSize
+				)
+				if high > len(elements) {
+					high = len(elements)
+				}
+
+				results[chunk] = transform(goiter.OfElements(elements[low:high])).ToSlice()
+			}
+		}()
+	}
+
+	// Wait for all goroutines to complete
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// Combine chunks into a single flat slice
+	return goiter.FlattenArraySlice(results), nil
+}
+
+// doParallelAdaptive does the grunt work of parallel processing with a number of goroutines chosen by the caller, where
+// the decision to execute goroutines is based on the measured cost of transforming a sample of elements
+func doParallelAdaptive(
+	source *goiter.Iter,
+	transform func(*goiter.Iter) *goiter.Iter,
+	finisher func(*goiter.Iter) *goiter.Iter,
+	workers int,
+) []interface{} {
+	if transform == nil {
+		return doParallel(source, transform, finisher, 0, NumberOfGoroutines)
+	}
+
+	// Transform a sample sequentially, measuring the cost per element
+	var (
+		elements   = source.ToSlice()
+		sampleSize = parallelSampleSize
+	)
+	if sampleSize > len(elements) {
+		sampleSize = len(elements)
+	}
+
+	start := time.Now()
+	flatData := transform(goiter.OfElements(elements[:sampleSize])).ToSlice()
+	cost := time.Since(start) / time.Duration(sampleSize+1)
+
+	// Transform the rest sequentially if it is estimated to be quick, otherwise have the goroutines claim chunks that are
+	// estimated to take parallelChunkDuration, with at least one chunk per goroutine
+	if rest := elements[sampleSize:]; len(rest) > 0 {
+		if (workers < 2) || (cost*time.Duration(len(rest)) < parallelMinDuration) {
+			flatData = append(flatData, transform(goiter.OfElements(rest)).ToSlice()...)
+		} else {
+			chunkSize := int(parallelChunkDuration / (cost + 1))
+			if maxChunkSize := (len(rest) + workers - 1) / workers; chunkSize > maxChunkSize {
+				chunkSize = maxChunkSize
+			}
+			if chunkSize < 1 {
+				chunkSize = 1
+			}
+
+			flatData = append(flatData, doParallelChunks(rest, transform, workers, chunkSize)...)
+		}
+	}
+
+	// If the finisher is non-nil, apply it afterwards - it cannot be done in parallel
+	if finisher != nil {
+		flatData = finisher(goiter.Of(flatData...)).ToSlice()
+	}
+
+	return flatData
+}
+
 // Stream is based on a source iterator, and provides a streaming facility where items can be transformed one by one as they are iterated into a new set, and possibly apply further transforms on the new set.
 // A Stream is effectively a kind of builder pattern, building up a set of transforms from an input data set to an output data set.
 //
@@ -153,13 +342,120 @@ func doParallel(
 // The order of operations is exactly as indicated - filter then map each element one by one into a new set, finally remove duplicates from then sort the set.
 // The result will be []int{2,4,6,8}.
 type Stream struct {
-	source    *goiter.Iter
+	source    func() *goiter.Iter
 	transform func(*goiter.Iter) *goiter.Iter
 	finite    bool
+	closer    *closer
+	metrics   MetricsHook
+	nilPolicy NilPolicy
+}
+
+// CloseErrors is the error returned by Stream.Close when one or more functions registered with OnClose return an error.
+// The errors are in the order the functions were registered in.
+type CloseErrors []error
+
+// Error is the error message of each error, separated by a semicolon
+func (e CloseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// closer is a function registered with Stream.OnClose, along with any functions registered before it.
+// A nil *closer has no functions.
+type closer struct {
+	parent *closer
+	f      func() error
+	once   sync.Once
+	errs   CloseErrors
+}
+
+// close calls the parent functions and then f, at most once, returning all errors
+func (c *closer) close() CloseErrors {
+	if c == nil {
+		return nil
+	}
+
+	c.once.Do(func() {
+		c.errs = append(c.errs, c.parent.close()...)
+		if err := c.f(); err != nil {
+			c.errs = append(c.errs, err)
+		}
+	})
+
+	return c.errs
 }
 
-// construct handles the details common to all constructor functions
+// construct handles the details common to all constructor functions.
+// The source can only be iterated once, so the consumer that exhausts it is recorded, and any later attempt to read the source
+// panics with a description of that consumer.
 func construct(source *goiter.Iter, finite bool) Stream {
+	var consumedBy []interface{}
+
+	return constructFunc(
+		func() *goiter.Iter {
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if consumedBy != nil {
+						panic(fmt.Sprintf(ErrStreamConsumed, consumedBy...))
+					}
+
+					if source.Next() {
+						return source.Value(), true
+					}
+
+					consumedBy = consumer()
+					return nil, false
+				},
+			)
+		},
+		finite,
+	)
+}
+
+var (
+	// gostreamPrefix and goiterPrefix are the prefixes of the names of functions in this package and goiter
+	gostreamPrefix = reflect.TypeOf(Stream{}).PkgPath() + "."
+	goiterPrefix   = reflect.TypeOf(goiter.Iter{}).PkgPath() + "."
+)
+
+// consumer returns the function of this package that is consuming a Stream, and where it was called from,
+// by examining the call stack from the innermost frame outwards until the first frame outside of this package and goiter.
+// EG, if the caller called Finisher.ToSlice, the result is {"Finisher.ToSlice", "caller.go:10"}.
+func consumer() []interface{} {
+	var (
+		pcs    = make([]uintptr, 64)
+		frames = runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+		name   = "Stream.Iter"
+	)
+
+	for {
+		frame, more := frames.Next()
+
+		switch {
+		case strings.HasPrefix(frame.Function, goiterPrefix):
+		case strings.HasPrefix(frame.Function, gostreamPrefix):
+			// Strip package and any closure suffix
+			name = strings.TrimPrefix(frame.Function, gostreamPrefix)
+			if i := strings.Index(name, ".func"); i >= 0 {
+				name = name[:i]
+			}
+		default:
+			return []interface{}{name, fmt.Sprintf("%s:%d", frame.File, frame.Line)}
+		}
+
+		if !more {
+			return []interface{}{name, "unknown location"}
+		}
+	}
+}
+
+// constructFunc is like construct, except that the source is provided by a function that is called each time the Stream is iterated.
+// If the function returns a new iterator each time, then the Stream can be iterated more than once.
+func constructFunc(source func() *goiter.Iter, finite bool) Stream {
 	return Stream{
 		source:    source,
 		transform: nil,
@@ -177,6 +473,131 @@ func Of(items ...interface{}) Stream {
 	)
 }
 
+// OfOptionals constructs a stream of the values of the present optionals, ignoring empty optionals
+func OfOptionals(opts ...gooptional.Optional) Stream {
+	values := make([]interface{}, 0, len(opts))
+	for _, opt := range opts {
+		if !opt.IsEmpty() {
+			values = append(values, opt.MustGet())
+		}
+	}
+
+	return Of(values...)
+}
+
+// ZipSlices constructs a stream of the result of calling the combiner with the elements at each index of the given slices
+// or arrays, which can have any element types, such as to convert columns into records.
+// The stream has as many elements as the shortest slice.
+// Panics if any of the slices are not a slice or array.
+func ZipSlices(combiner func(values ...interface{}) interface{}, slices ...interface{}) Stream {
+	var (
+		vals = make([]reflect.Value, len(slices))
+		n    = 0
+	)
+
+	for i, slice := range slices {
+		vals[i] = reflect.ValueOf(slice)
+		if k := vals[i].Kind(); (k != reflect.Slice) && (k != reflect.Array) {
+			panic(fmt.Sprintf("slices[%d] must be a slice or array", i))
+		}
+
+		if l := vals[i].Len(); (i == 0) || (l < n) {
+			n = l
+		}
+	}
+
+	index := 0
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				if index >= n {
+					return nil, false
+				}
+
+				values := make([]interface{}, len(vals))
+				for i, val := range vals {
+					values[i] = val.Index(index).Interface()
+				}
+				index++
+
+				return combiner(values...), true
+			},
+		),
+		true,
+	)
+}
+
+// Subsets constructs a stream of every subset of the given items, each of which is a new []interface{}.
+// Subsets are produced lazily in order of increasing size, and subsets of the same size are in lexicographic order of
+// item positions, so only one subset is in memory at a time. The items are assumed to be distinct.
+// If the optional size is provided, only subsets of that size are produced.
+// Panics if size < 0.
+func Subsets(items []interface{}, size ...int) Stream {
+	var (
+		n       = len(items)
+		k       = 0
+		maxK    = n
+		indices []int
+		started bool
+	)
+
+	if len(size) > 0 {
+		if size[0] < 0 {
+			panic("size must be >= 0")
+		}
+
+		k, maxK = size[0], size[0]
+	}
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				if !started {
+					started = true
+				} else {
+					// Advance the rightmost index that can be advanced, and reset the indexes after it
+					i := k - 1
+					for (i >= 0) && (indices[i] == n-k+i) {
+						i--
+					}
+
+					if i >= 0 {
+						indices[i]++
+						for j := i + 1; j < k; j++ {
+							indices[j] = indices[j-1] + 1
+						}
+					} else {
+						// All subsets of size k have been produced
+						k++
+						indices = nil
+					}
+				}
+
+				if (k > maxK) || (k > n) {
+					return nil, false
+				}
+
+				if indices == nil {
+					// First subset of size k
+					indices = make([]int, k)
+					for i := range indices {
+						indices[i] = i
+					}
+				}
+
+				subset := make([]interface{}, k)
+				for i, index := range indices {
+					subset[i] = items[index]
+				}
+
+				return subset, true
+			},
+		),
+		true,
+	)
+}
+
 // OfIterables constructs a stream of values returned by any number of iterables
 func OfIterables(iterables ...goiter.Iterable) Stream {
 	return construct(
@@ -186,7 +607,8 @@ func OfIterables(iterables ...goiter.Iterable) Stream {
 }
 
 // Iterate returns a stream of an infinite iterative calculation, f(seed), f(f(seed)), ...
-// Since the series is infinite, some combination of Stream.First() and/or Finisher.Limit() will be required to terminate the series.
+// Since the series is infinite, some combination of Finisher.FindFirst() and/or one of Stream.Limit(), Stream.TakeWhile(),
+// Finisher.Limit(), or Finisher.TakeWhile() will be required to terminate the series.
 func Iterate(seed interface{}, f func(interface{}) interface{}) Stream {
 	acculumator := seed
 
@@ -206,8 +628,11 @@ func Iterate(seed interface{}, f func(interface{}) interface{}) Stream {
 func (s Stream) Transform(t func(*goiter.Iter) *goiter.Iter) Stream {
 	return Stream{
 		source:    s.source,
-		transform: compose(s.transform, t),
+		transform: compose(s.transform, withNilPolicy(s.nilPolicy, t)),
 		finite:    s.finite,
+		closer:    s.closer,
+		metrics:   s.metrics,
+		nilPolicy: s.nilPolicy,
 	}
 }
 
@@ -230,6 +655,17 @@ func (s Stream) Filter(f func(element interface{}) bool) Stream {
 	)
 }
 
+// FilterMatching returns a new stream of all elements that pass all or any of the given predicates, according to the
+// mode, in a single stage. Predicates are evaluated in order, stopping as soon as the result is known.
+// With no predicates, MatchAll passes every element and MatchAny passes none.
+func (s Stream) FilterMatching(mode FilterMode, preds ...func(element interface{}) bool) Stream {
+	if mode == MatchAny {
+		return s.Filter(Or(preds...))
+	}
+
+	return s.Filter(And(preds...))
+}
+
 // FilterNot returns a new stream of all elements that do not pass the given predicate
 func (s Stream) FilterNot(f func(element interface{}) bool) Stream {
 	return s.Filter(
@@ -256,6 +692,169 @@ func (s Stream) Map(f func(element interface{}) interface{}) Stream {
 	)
 }
 
+// MapIf returns a new stream of the result of mapping each element with then if it passes the given predicate, or with
+// otherwise if it does not
+func (s Stream) MapIf(
+	pred func(element interface{}) bool,
+	then func(element interface{}) interface{},
+	otherwise func(element interface{}) interface{},
+) Stream {
+	return s.Map(
+		func(element interface{}) interface{} {
+			if pred(element) {
+				return then(element)
+			}
+
+			return otherwise(element)
+		},
+	)
+}
+
+// MapWhere returns a new stream of the result of mapping each element with then if it passes the given predicate,
+// and unchanged if it does not
+func (s Stream) MapWhere(pred func(element interface{}) bool, then func(element interface{}) interface{}) Stream {
+	return s.MapIf(
+		pred,
+		then,
+		func(element interface{}) interface{} {
+			return element
+		},
+	)
+}
+
+// MapOrElse returns a new stream that maps each element with f, or with fallback if f returns an error, so that
+// recoverable failures of individual elements substitute a value rather than stopping the stream
+func (s Stream) MapOrElse(
+	f func(element interface{}) (interface{}, error),
+	fallback func(element interface{}, err error) interface{},
+) Stream {
+	return s.Map(
+		func(element interface{}) interface{} {
+			value, err := f(element)
+			if err != nil {
+				return fallback(element, err)
+			}
+
+			return value
+		},
+	)
+}
+
+// MapWithTimeout returns a new stream that maps each element with f, where f is run in a goroutine and given at most
+// d to complete, so that one hung element cannot freeze the whole stream.
+// If f does not complete in time, the element is mapped with onTimeout instead: if onTimeout returns an error, the
+// stream panics with the error, otherwise the result of onTimeout is the mapped element.
+// If onTimeout is nil, the stream panics with ErrMapTimeout when an element times out.
+// A panic in f is rethrown.
+// Note that a goroutine running f cannot be stopped, it continues until f returns, and its result is discarded.
+// Panics if d <= 0.
+func (s Stream) MapWithTimeout(
+	f func(element interface{}) interface{},
+	d time.Duration,
+	onTimeout func(element interface{}) (interface{}, error),
+) Stream {
+	if d <= 0 {
+		panic("d must be > 0")
+	}
+
+	type result struct {
+		value    interface{}
+		panicked bool
+		panicVal interface{}
+	}
+
+	return s.Map(
+		func(element interface{}) interface{} {
+			// Buffered, so the goroutine can exit after a timeout
+			results := make(chan result, 1)
+
+			go func() {
+				var res result
+				defer func() {
+					if err := recover(); err != nil {
+						res.panicked, res.panicVal = true, err
+					}
+					results <- res
+				}()
+
+				res.value = f(element)
+			}()
+
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+
+			select {
+			case res := <-results:
+				if res.panicked {
+					panic(res.panicVal)
+				}
+
+				return res.value
+
+			case <-timer.C:
+				if onTimeout == nil {
+					panic(fmt.Sprintf(ErrMapTimeout, element, d))
+				}
+
+				value, err := onTimeout(element)
+				if err != nil {
+					panic(err)
+				}
+
+				return value
+			}
+		},
+	)
+}
+
+// MapBatched returns a new stream that collects elements into batches of batchSize elements, and replaces each batch
+// with the result of calling the given function with it, such as a bulk lookup. The results of each batch are returned in
+// order, and can have any number of elements. The last batch may have fewer elements.
+// When executed by the parallel methods of Finisher, each goroutine forms its own batches.
+// Panics if batchSize < 1.
+func (s Stream) MapBatched(batchSize int, f func(batch []interface{}) []interface{}) Stream {
+	if batchSize < 1 {
+		panic("batchSize must be > 0")
+	}
+
+	return s.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				results   []interface{}
+				exhausted bool
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					for (len(results) == 0) && (!exhausted) {
+						batch := make([]interface{}, 0, batchSize)
+						for (len(batch) < batchSize) && it.Next() {
+							batch = append(batch, it.Value())
+						}
+
+						if len(batch) < batchSize {
+							exhausted = true
+						}
+
+						if len(batch) > 0 {
+							results = f(batch)
+						}
+					}
+
+					if len(results) == 0 {
+						return nil, false
+					}
+
+					val := results[0]
+					results = results[1:]
+
+					return val, true
+				},
+			)
+		},
+	)
+}
+
 // Peek returns a stream that calls a function that examines each value and performs an additional operation
 func (s Stream) Peek(f func(interface{})) Stream {
 	return s.Transform(
@@ -275,23 +874,328 @@ func (s Stream) Peek(f func(interface{})) Stream {
 	)
 }
 
-// Iter returns an iterator of the elements in this Stream.
-// Note that a stream can only be iterated once by a single *goiter.Iter instance.
-func (s Stream) Iter() *goiter.Iter {
-	it := s.source
-	if s.transform != nil {
-		it = s.transform(it)
-	}
-
-	return goiter.NewIter(
-		func() (interface{}, bool) {
-			if it.Next() {
-				return it.Value(), true
-			}
+// Limit returns a new stream that only iterates the first n elements, ignoring the rest.
+// If the Stream is infinite, the new Stream is finite.
+// Since the limit depends on the order of elements, this Stream is iterated sequentially by the parallel methods of Finisher,
+// and only transforms applied after the limit are executed in parallel.
+func (s Stream) Limit(n uint) Stream {
+	return s.sequentialTransform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var elementsRead uint
 
-			return nil, false
-		},
-	)
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if (elementsRead == n) || (!it.Next()) {
+						return nil, false
+					}
+
+					elementsRead++
+					return it.Value(), true
+				},
+			)
+		},
+		true,
+	)
+}
+
+// TakeWhile returns a new stream that iterates elements until the first element that does not pass the given predicate,
+// ignoring that element and the rest.
+// If the Stream is infinite, the new Stream is marked as finite, since it is assumed that some element will not pass.
+// Since the result depends on the order of elements, this Stream is iterated sequentially by the parallel methods of Finisher,
+// and only transforms applied after TakeWhile are executed in parallel.
+func (s Stream) TakeWhile(f func(element interface{}) bool) Stream {
+	return s.sequentialTransform(
+		func(it *goiter.Iter) *goiter.Iter {
+			done := false
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if done || (!it.Next()) {
+						return nil, false
+					}
+
+					if val := it.Value(); f(val) {
+						return val, true
+					}
+
+					done = true
+					return nil, false
+				},
+			)
+		},
+		true,
+	)
+}
+
+// MapWhile returns a new stream of the results of mapping each element with the given function, until the function
+// returns false, ignoring that result and the rest of the elements.
+// It is a combination of Map and TakeWhile, for cases where the decision to stop depends on the mapping.
+// If the Stream is infinite, the new Stream is marked as finite, since it is assumed that the function will return false.
+// Since the result depends on the order of elements, this Stream is iterated sequentially by the parallel methods of Finisher,
+// and only transforms applied after MapWhile are executed in parallel.
+func (s Stream) MapWhile(f func(element interface{}) (interface{}, bool)) Stream {
+	return s.sequentialTransform(
+		func(it *goiter.Iter) *goiter.Iter {
+			done := false
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if done || (!it.Next()) {
+						return nil, false
+					}
+
+					if val, ok := f(it.Value()); ok {
+						return val, true
+					}
+
+					done = true
+					return nil, false
+				},
+			)
+		},
+		true,
+	)
+}
+
+// PreSorted returns a new stream with the elements sorted by the given comparator, for cases where sorting must happen
+// before further transforms, rather than in the Finisher.
+// All elements are buffered when the first element is read. Since sorting depends on all elements, this Stream is
+// iterated and sorted sequentially by the parallel methods of Finisher, and only transforms applied after PreSorted are
+// executed in parallel.
+// Panics if the Stream is infinite.
+func (s Stream) PreSorted(less func(element1, element2 interface{}) bool) Stream {
+	s.AndThen().panicIfInfinite()
+
+	return s.sequentialTransform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var sortedIter *goiter.Iter
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if sortedIter == nil {
+						sorted := it.ToSlice()
+						sort.Slice(sorted, func(i, j int) bool {
+							return less(sorted[i], sorted[j])
+						})
+
+						sortedIter = goiter.OfElements(sorted)
+					}
+
+					if sortedIter.Next() {
+						return sortedIter.Value(), true
+					}
+
+					return nil, false
+				},
+			)
+		},
+		false,
+	)
+}
+
+// PreDistinct returns a new stream of distinct elements only, for cases where duplicates must be removed before further
+// transforms, such as an expensive Map of distinct ids, rather than in the Finisher.
+// The elements read are stored in the given StateStore, or in a new MapStateStore for each iteration if none is given.
+// A given StateStore keeps the elements read across iterations, so a second iteration of a replayable Stream only
+// returns elements that were not read by the first.
+// Since the result depends on the order of elements, this Stream is iterated sequentially by the parallel methods of
+// Finisher, and only transforms applied after PreDistinct are executed in parallel.
+// Elements that cannot be map keys are handled the same as Finisher.Distinct.
+// Panics if more than one StateStore is given.
+func (s Stream) PreDistinct(store ...StateStore) Stream {
+	checkStateStore(store)
+
+	return s.sequentialTransform(
+		func(it *goiter.Iter) *goiter.Iter {
+			keep := distinctFilter(store)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					for it.Next() {
+						if val := it.Value(); keep(val) {
+							return val, true
+						}
+					}
+
+					return nil, false
+				},
+			)
+		},
+		false,
+	)
+}
+
+// sequentialTransform returns a new Stream whose source is the given transform applied to this Stream, which is used for
+// transforms that depend on the order of elements.
+// Since the parallel methods of Finisher only split up the source, the transform and all transforms before it are applied
+// sequentially, while only transforms composed after it are applied in parallel.
+// The new Stream is finite if this Stream is finite or finite is true.
+func (s Stream) sequentialTransform(t func(*goiter.Iter) *goiter.Iter, finite bool) Stream {
+	return Stream{
+		source: func() *goiter.Iter {
+			return withNilPolicy(s.nilPolicy, t)(s.Iter())
+		},
+		transform: nil,
+		finite:    s.finite || finite,
+		closer:    s.closer,
+		metrics:   s.metrics,
+		nilPolicy: s.nilPolicy,
+	}
+}
+
+// Snapshot returns a Stream of the elements of this Stream, which are read into a slice and this Stream closed when the
+// result is first iterated. Unlike this Stream, the result can be iterated any number of times, and each call to Iter
+// returns a new iterator over the snapshot, so it is a well behaved goiter.Iterable for other goiter consumers.
+// Unlike Cache, all elements are read at once, and the snapshot is safe for use by multiple goroutines.
+// Panics if this Stream is infinite.
+func (s Stream) Snapshot() Stream {
+	s.AndThen().panicIfInfinite()
+
+	var (
+		once     sync.Once
+		elements []interface{}
+	)
+
+	return constructFunc(
+		func() *goiter.Iter {
+			once.Do(func() { elements = s.AndThen().ToSlice() })
+
+			return goiter.OfElements(elements)
+		},
+		true,
+	)
+}
+
+// Cache returns a Stream that memoizes the elements of this Stream as they are read, so that the result can be iterated
+// any number of times, such as to execute more than one terminal.
+// No elements are read until the result is first iterated. Each iteration replays the cached elements, then reads and caches
+// further elements as needed. Iterations can be interleaved, but the result is not safe for use by multiple goroutines.
+//
+// If the optional maxElements value is provided, at most that many elements are cached.
+// When an iteration reads an element beyond the maximum, the cache is discarded and that iteration continues reading elements
+// without caching them; any other iteration panics with ErrCacheOverflow.
+func (s Stream) Cache(maxElements ...uint) Stream {
+	var (
+		cache     []interface{}
+		upstream  *goiter.Iter
+		exhausted bool
+		overflow  bool
+	)
+
+	return constructFunc(
+		func() *goiter.Iter {
+			if overflow {
+				panic(ErrCacheOverflow)
+			}
+
+			if upstream == nil {
+				upstream = s.Iter()
+			}
+
+			var (
+				index int
+				// owner is true if this iteration overflowed the cache, and is the only one that can continue
+				owner bool
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if owner {
+						if upstream.Next() {
+							return upstream.Value(), true
+						}
+
+						return nil, false
+					}
+
+					if overflow {
+						panic(ErrCacheOverflow)
+					}
+
+					// Replay cached elements first
+					if index < len(cache) {
+						index++
+						return cache[index-1], true
+					}
+
+					if exhausted {
+						return nil, false
+					}
+
+					if !upstream.Next() {
+						exhausted = true
+						return nil, false
+					}
+
+					val := upstream.Value()
+					if (len(maxElements) > 0) && (uint(len(cache)) == maxElements[0]) {
+						// Discard the cache, this iteration continues without it
+						cache, overflow, owner = nil, true, true
+						return val, true
+					}
+
+					cache = append(cache, val)
+					index++
+					return val, true
+				},
+			)
+		},
+		s.finite,
+	)
+}
+
+// OnClose returns a new Stream that calls the given function when it is closed, for streams that are backed by resources such as
+// files, database rows, or network connections.
+// Every terminal method closes the Stream when it completes, returns early, or panics, and a Stream is also closed when an
+// iterator returned by Stream.Iter or Finisher.Iter is exhausted.
+// Closing calls all functions registered on the Stream in the order they were registered, and only does so once.
+// Terminals that return an error include any close errors.
+//
+// Functions registered on this Stream are not registered on the result of Stream.Cache, which closes this Stream when it has
+// cached all elements.
+func (s Stream) OnClose(f func() error) Stream {
+	return Stream{
+		source:    s.source,
+		transform: s.transform,
+		finite:    s.finite,
+		closer: &closer{
+			parent: s.closer,
+			f:      f,
+		},
+		metrics:   s.metrics,
+		nilPolicy: s.nilPolicy,
+	}
+}
+
+// Close calls the functions registered with OnClose, if they have not already been called.
+// If any of them return an error, a CloseErrors is returned. Every call returns the same result.
+func (s Stream) Close() error {
+	if errs := s.closer.close(); len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Iter returns an iterator of the elements in this Stream.
+// Note that a stream can only be iterated once by a single *goiter.Iter instance.
+// The Stream is closed when the iterator is exhausted.
+func (s Stream) Iter() *goiter.Iter {
+	it := s.source()
+	if s.transform != nil {
+		it = s.transform(it)
+	}
+
+	return goiter.NewIter(
+		func() (interface{}, bool) {
+			if it.Next() {
+				return it.Value(), true
+			}
+
+			s.Close()
+			return nil, false
+		},
+	)
 }
 
 // AndThen returns a Finisher, which performs additional post processing on the results of the transforms in this Stream.
@@ -301,14 +1205,27 @@ func (s Stream) AndThen() Finisher {
 		source:    s,
 		transform: nil,
 		finite:    s.finite,
+		partial:   &partialIteration{},
 	}
 }
 
 // ==== Finisher
 
+// Group is a key and the Stream of elements that have that key, as returned by Finisher.GroupByStream
+type Group struct {
+	Key      interface{}
+	Elements Stream
+}
+
 const (
 	// ErrInfiniteFinisher is thrown when terminal methods are called on an infinite Finisher
 	ErrInfiniteFinisher = "The Finisher is infinite, no terminal methods can be called unless Limit is called first"
+	// ErrPeekPanic is the format of the message thrown when the function passed to PeekIndexed panics with a value that
+	// is not an error. The placeholders are the label, the index of the element, and the original panic value.
+	ErrPeekPanic = "%s: panic at element %d: %v"
+	// ErrNaturalOrder is the format of the message thrown when SortedNatural compares elements that have no natural
+	// order with each other. The placeholders are the types of the elements.
+	ErrNaturalOrder = "SortedNatural cannot compare elements of types %T and %T, elements must all be ints, uints, floats, strings, time.Time, *big.Int, *big.Rat, or *big.Float"
 )
 
 // Finisher does two things:
@@ -331,6 +1248,14 @@ type Finisher struct {
 	source    Stream
 	transform func(*goiter.Iter) *goiter.Iter
 	finite    bool
+	partial   *partialIteration
+}
+
+// partialIteration is the iteration of a Finisher that FindFirst reads, so that each call to FindFirst reads the next
+// element of the same iteration, and a terminal called after FindFirst reads the remaining elements.
+type partialIteration struct {
+	it       *goiter.Iter
+	finished bool
 }
 
 // panicIfInfinite panics if the Finisher is infinite
@@ -340,45 +1265,130 @@ func (fin Finisher) panicIfInfinite() {
 	}
 }
 
+// terminal is the bookkeeping common to all terminal methods
+type terminal struct {
+	name     string
+	fin      Finisher
+	hook     MetricsHook
+	start    time.Time
+	elements uint64
+}
+
+// terminal starts a terminal method of the given name, which must defer a call to done
+func (fin Finisher) terminal(name string) *terminal {
+	hook := fin.source.metricsHook()
+	if hook != nil {
+		hook.OnStageStart(name)
+	}
+
+	return &terminal{
+		name:  name,
+		fin:   fin,
+		hook:  hook,
+		start: time.Now(),
+	}
+}
+
+// Iter returns an iterator of the elements of the Finisher, which counts the elements read.
+// Panics if the Finisher is infinite.
+func (t *terminal) Iter() *goiter.Iter {
+	it := t.fin.Iter()
+
+	return goiter.NewIter(
+		func() (interface{}, bool) {
+			if it.Next() {
+				t.elements++
+				return it.Value(), true
+			}
+
+			return nil, false
+		},
+	)
+}
+
+// done closes the Stream, reports the end of the terminal to the MetricsHook, and returns any error from closing.
+// Terminals that do not return an error ignore the result.
+func (t *terminal) done() error {
+	err := t.fin.source.Close()
+
+	if t.hook != nil {
+		t.hook.OnTerminalEnd(t.name, t.elements, time.Since(t.start))
+	}
+
+	return err
+}
+
 // FindFirst returns the optional first element of applying any tranforms to the stream source.
 // May be called any number of times at any time.
 // Exhausts one or more items of the source until an item that satisfies the current transforms is found, if any.
 // If no such item is found, an empty Optional is returned, else an Optional of the transformed item is returned.
+// Each call continues the same iteration of this Finisher, so that transforms such as Limit apply across calls, and a
+// terminal called afterwards reads the remaining elements. Once the iteration is finished, an empty Optional is returned.
 //
 // Note that it is possible for the transforms to transform an item into a nil value, resulting in an empty Optional.
-// A such, an empty result does not necessarily indicate there are no more results in the Stream.
-// However, Stream is based on goiter.Iter, which panics if Next() is called again after a previous Next() call returned false.
+// A such, an empty result does not necessarily indicate there are no more results in the Stream, unless the Stream has
+// the SkipNils or FailOnNil policy (see Stream.WithNilPolicy).
 // Taken together, the FindFirst() result cannot distinguish between a nil element and the end of the stream.
 func (fin Finisher) FindFirst() gooptional.Optional {
 	var val interface{}
 
-	it := fin.source.Iter()
-	if fin.transform != nil {
-		it = fin.transform(it)
+	if fin.partial.it == nil {
+		it := fin.pipeline()
+
+		fin.partial.it = goiter.NewIter(
+			func() (interface{}, bool) {
+				if it.Next() {
+					return it.Value(), true
+				}
+
+				fin.partial.finished = true
+				return nil, false
+			},
+		)
 	}
 
-	if it.Next() {
-		val = it.Value()
+	if (!fin.partial.finished) && fin.partial.it.Next() {
+		val = fin.partial.it.Value()
 	}
 
 	return gooptional.Of(val)
 }
 
+// pipeline returns an iterator of the transforms of the Finisher applied to the Stream.
+// Each call starts a new iteration, with new state for each transform.
+func (fin Finisher) pipeline() *goiter.Iter {
+	it := fin.source.Iter()
+	if fin.transform != nil {
+		it = fin.transform(it)
+	}
+
+	return it
+}
+
 // ==== Transforms
 
 // Transform composes the current transform with a new one
 func (fin Finisher) Transform(f func(*goiter.Iter) *goiter.Iter) Finisher {
 	return Finisher{
 		source:    fin.source,
-		transform: compose(fin.transform, f),
+		transform: compose(fin.transform, withNilPolicy(fin.source.nilPolicy, f)),
 		finite:    fin.finite,
+		partial:   &partialIteration{},
 	}
 }
 
 // Filter returns a new Finisher of all elements that pass the given predicate
 func (fin Finisher) Filter(f func(element interface{}) bool) Finisher {
+	return fin.filterEach(func() func(element interface{}) bool { return f })
+}
+
+// filterEach is Filter with a predicate created by newPred for each iteration, for predicates that keep state about
+// the elements read
+func (fin Finisher) filterEach(newPred func() func(element interface{}) bool) Finisher {
 	return fin.Transform(
 		func(it *goiter.Iter) *goiter.Iter {
+			f := newPred()
+
 			return goiter.NewIter(
 				func() (interface{}, bool) {
 					for it.Next() {
@@ -394,6 +1404,17 @@ func (fin Finisher) Filter(f func(element interface{}) bool) Finisher {
 	)
 }
 
+// FilterMatching returns a new Finisher of all elements that pass all or any of the given predicates, according to the
+// mode, in a single stage. Predicates are evaluated in order, stopping as soon as the result is known.
+// With no predicates, MatchAll passes every element and MatchAny passes none.
+func (fin Finisher) FilterMatching(mode FilterMode, preds ...func(element interface{}) bool) Finisher {
+	if mode == MatchAny {
+		return fin.Filter(Or(preds...))
+	}
+
+	return fin.Filter(And(preds...))
+}
+
 // FilterNot returns a new stream of all elements that do not pass the given predicate
 func (fin Finisher) FilterNot(f func(element interface{}) bool) Finisher {
 	return fin.Filter(
@@ -403,22 +1424,39 @@ func (fin Finisher) FilterNot(f func(element interface{}) bool) Finisher {
 	)
 }
 
-// Distinct returns a Finisher of distinct elements only
-func (fin Finisher) Distinct() Finisher {
-	alreadyRead := map[interface{}]bool{}
-
-	return fin.Filter(
-		func(element interface{}) bool {
-			if !alreadyRead[element] {
-				alreadyRead[element] = true
-				return true
-			}
+// FlattenOptionals returns a new Finisher of the values of elements that are present optionals, ignoring empty optionals.
+// Panics during iteration if an element is not a gooptional.Optional.
+func (fin Finisher) FlattenOptionals() Finisher {
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					for it.Next() {
+						if opt := it.Value().(gooptional.Optional); !opt.IsEmpty() {
+							return opt.MustGet(), true
+						}
+					}
 
-			return false
+					return nil, false
+				},
+			)
 		},
 	)
 }
 
+// Distinct returns a Finisher of distinct elements only.
+// The elements read are stored in the given StateStore, or in a new MapStateStore for each iteration if none is given.
+// A given StateStore keeps the elements read across iterations, so a second iteration of a replayable Stream only
+// returns elements that were not read by the first.
+// Elements that cannot be map keys, such as maps and slices, are compared with reflect.DeepEqual if no StateStore is
+// given, otherwise iteration panics with ErrDistinctUnhashable. See DistinctFunc for a faster alternative.
+// Panics if more than one StateStore is given.
+func (fin Finisher) Distinct(store ...StateStore) Finisher {
+	checkStateStore(store)
+
+	return fin.filterEach(func() func(element interface{}) bool { return distinctFilter(store) })
+}
+
 // Duplicates returns a stream of duplicate elements only
 func (fin Finisher) Duplicates() Finisher {
 	alreadyRead := map[interface{}]bool{}
@@ -460,38 +1498,754 @@ func (fin Finisher) Skip(n int) Finisher {
 						return it.Value(), true
 					}
 
-					return nil, false
+					return nil, false
+				},
+			)
+		},
+	)
+}
+
+// Rolling returns a new Finisher of the result of applying the aggregate function to a sliding window of the last n
+// elements, producing one result for each element from the nth element onwards, such as a moving average or rolling max.
+// If there are fewer than n elements, there are no results.
+// The window is maintained incrementally in a buffer that is reused, so the slice passed to the function is only valid for
+// the duration of the call. See RollingAggregate for aggregates that can be updated without examining the whole window.
+// Panics if n < 1.
+func (fin Finisher) Rolling(n int, agg func(window []interface{}) interface{}) Finisher {
+	if n < 1 {
+		panic("n must be > 0")
+	}
+
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			buf := make([]interface{}, 0, 2*n)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					for it.Next() {
+						// When the buffer is full, move the last n - 1 elements to the front, so that the cost of
+						// sliding the window is amortized over n elements
+						if len(buf) == cap(buf) {
+							buf = buf[:copy(buf, buf[len(buf)-n+1:])]
+						}
+
+						if buf = append(buf, it.Value()); len(buf) >= n {
+							return agg(buf[len(buf)-n:]), true
+						}
+					}
+
+					return nil, false
+				},
+			)
+		},
+	)
+}
+
+// RollingAggregate is the same as Rolling, except that the aggregate is updated incrementally as the window slides.
+// The add function returns the aggregate with an element added to the window, and the remove function returns the
+// aggregate with the oldest element removed from the window, so that each result takes constant time regardless of the
+// window size, such as for a moving sum. The identity is the aggregate of an empty window.
+// Panics if n < 1.
+func (fin Finisher) RollingAggregate(
+	n int,
+	identity interface{},
+	add func(aggregate, element interface{}) interface{},
+	remove func(aggregate, element interface{}) interface{},
+) Finisher {
+	if n < 1 {
+		panic("n must be > 0")
+	}
+
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				aggregate = identity
+				ring      = make([]interface{}, n)
+				next      int
+				count     int
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					for it.Next() {
+						val := it.Value()
+
+						// Remove the oldest element once the window is full, it is replaced by the newest
+						if count == n {
+							aggregate = remove(aggregate, ring[next])
+						} else {
+							count++
+						}
+
+						aggregate = add(aggregate, val)
+						ring[next] = val
+						next = (next + 1) % n
+
+						if count == n {
+							return aggregate, true
+						}
+					}
+
+					return nil, false
+				},
+			)
+		},
+	)
+}
+
+// WindowByTime returns a new Finisher of []interface{} windows, where each window contains consecutive elements whose
+// timestamps fall into the same tumbling time window.
+// Windows are aligned to multiples of the window duration since the zero time, as computed by time.Time.Truncate,
+// so a window of one minute starts at the beginning of each minute.
+// Elements are expected to be ordered by timestamp - an element in a different window than the previous element ends
+// the current window, even if its timestamp is earlier. Windows with no elements are not produced.
+// Panics if window <= 0.
+func (fin Finisher) WindowByTime(extractTime func(element interface{}) time.Time, window time.Duration) Finisher {
+	if window <= 0 {
+		panic("window must be > 0")
+	}
+
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				current      []interface{}
+				currentStart time.Time
+				done         bool
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					for (!done) && it.Next() {
+						val := it.Value()
+						start := extractTime(val).Truncate(window)
+
+						if (current == nil) || start.Equal(currentStart) {
+							current = append(current, val)
+							currentStart = start
+							continue
+						}
+
+						// Element starts a new window, return the current one
+						result := current
+						current = []interface{}{val}
+						currentStart = start
+
+						return result, true
+					}
+
+					// Return the last window, if any
+					done = true
+					if current != nil {
+						result := current
+						current = nil
+
+						return result, true
+					}
+
+					return nil, false
+				},
+			)
+		},
+	)
+}
+
+// bufferedElement is an element passed through the channel of Finisher.Buffer, or a panic from reading the source
+type bufferedElement struct {
+	value    interface{}
+	panicked bool
+}
+
+// Buffer returns a new Finisher that reads elements into a buffer of n elements from a separate goroutine, decoupling a
+// bursty source from a slow consumer. The policy determines what happens when the consumer lags and the buffer is full.
+// Each iteration has its own buffer and goroutine, which is started when the first element is read, and stops when the
+// source is exhausted or the Stream is closed. If the source panics, the panic is rethrown when the consumer reaches
+// that point in the buffer.
+// Panics if n < 1.
+func (fin Finisher) Buffer(n int, policy OverflowPolicy) Finisher {
+	if n < 1 {
+		panic("n must be > 0")
+	}
+
+	var (
+		mu sync.Mutex
+		// running has the stop channel of each iteration whose goroutine is running
+		running = map[chan struct{}]bool{}
+	)
+
+	newFin := fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				ch      = make(chan bufferedElement, n)
+				stop    = make(chan struct{})
+				started bool
+			)
+
+			// produce reads the source into the buffer until it is exhausted or the Stream is closed
+			produce := func() {
+				defer func() {
+					mu.Lock()
+					delete(running, stop)
+					mu.Unlock()
+				}()
+
+				defer close(ch)
+
+				defer func() {
+					if err := recover(); err != nil {
+						select {
+						case ch <- bufferedElement{value: err, panicked: true}:
+						case <-stop:
+						}
+					}
+				}()
+
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+
+					if !it.Next() {
+						return
+					}
+					elem := bufferedElement{value: it.Value()}
+
+					switch policy {
+					case DropNewest:
+						select {
+						case ch <- elem:
+						default:
+						}
+
+					case DropOldest:
+						for sent := false; !sent; {
+							select {
+							case ch <- elem:
+								sent = true
+							default:
+								select {
+								case <-ch:
+								default:
+								}
+							}
+						}
+
+					default:
+						select {
+						case ch <- elem:
+						case <-stop:
+							return
+						}
+					}
+				}
+			}
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !started {
+						started = true
+
+						mu.Lock()
+						running[stop] = true
+						mu.Unlock()
+
+						go produce()
+					}
+
+					elem, ok := <-ch
+					if !ok {
+						return nil, false
+					}
+
+					if elem.panicked {
+						panic(elem.value)
+					}
+
+					return elem.value, true
+				},
+			)
+		},
+	)
+
+	// Stop the goroutines when the Stream is closed, in case the consumer stops before the source is exhausted
+	newFin.source = newFin.source.OnClose(func() error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for stop := range running {
+			close(stop)
+			delete(running, stop)
+		}
+
+		return nil
+	})
+
+	return newFin
+}
+
+// Replace returns a new Finisher that substitutes the replacement for the first limit elements that pass the given
+// predicate. As with strings.Replace, if limit < 0, there is no limit on the number of replacements.
+func (fin Finisher) Replace(match func(element interface{}) bool, replacement interface{}, limit int) Finisher {
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			replaced := 0
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !it.Next() {
+						return nil, false
+					}
+
+					val := it.Value()
+					if ((limit < 0) || (replaced < limit)) && match(val) {
+						replaced++
+						return replacement, true
+					}
+
+					return val, true
+				},
+			)
+		},
+	)
+}
+
+// ReplaceAll returns a new Finisher that substitutes the replacement for all elements that pass the given predicate
+func (fin Finisher) ReplaceAll(match func(element interface{}) bool, replacement interface{}) Finisher {
+	return fin.Replace(match, replacement, -1)
+}
+
+// PadTo returns a new Finisher that appends the given value as many times as needed for the Finisher to have at least n
+// elements. If there are already n or more elements, they are returned unchanged.
+func (fin Finisher) PadTo(n int, value interface{}) Finisher {
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				count     int
+				exhausted bool
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if (!exhausted) && it.Next() {
+						count++
+						return it.Value(), true
+					}
+					exhausted = true
+
+					if count < n {
+						count++
+						return value, true
+					}
+
+					return nil, false
+				},
+			)
+		},
+	)
+}
+
+// RotateLeft returns a new Finisher that cyclically shifts elements n positions to the left, so that the first n
+// elements come after the rest.
+// Only the first n elements are held in memory. If there are fewer than n elements, they are rotated by n modulo the
+// number of elements.
+func (fin Finisher) RotateLeft(n uint) Finisher {
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				head     []interface{}
+				headIter *goiter.Iter
+				started  bool
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !started {
+						started = true
+
+						// Buffer the first n elements
+						for (uint(len(head)) < n) && it.Next() {
+							head = append(head, it.Value())
+						}
+
+						if uint(len(head)) < n {
+							// All elements are buffered, rotate them
+							var rotated []interface{}
+							if len(head) > 0 {
+								r := n % uint(len(head))
+								rotated = append(append(rotated, head[r:]...), head[:r]...)
+							}
+
+							headIter = goiter.OfElements(rotated)
+						}
+					}
+
+					if headIter == nil {
+						// Return the rest of the elements, then the first n
+						if it.Next() {
+							return it.Value(), true
+						}
+
+						headIter = goiter.OfElements(head)
+					}
+
+					if headIter.Next() {
+						return headIter.Value(), true
+					}
+
+					return nil, false
+				},
+			)
+		},
+	)
+}
+
+// RotateRight returns a new Finisher that cyclically shifts elements n positions to the right, so that the last n
+// elements come before the rest.
+// Since the last n elements are not known until the source is exhausted, all elements are held in memory.
+// If there are fewer than n elements, they are rotated by n modulo the number of elements.
+func (fin Finisher) RotateRight(n uint) Finisher {
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var rotatedIter *goiter.Iter
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if rotatedIter == nil {
+						all := it.ToSlice()
+
+						var rotated []interface{}
+						if len(all) > 0 {
+							r := uint(len(all)) - (n % uint(len(all)))
+							rotated = append(append(rotated, all[r:]...), all[:r]...)
+						}
+
+						rotatedIter = goiter.OfElements(rotated)
+					}
+
+					if rotatedIter.Next() {
+						return rotatedIter.Value(), true
+					}
+
+					return nil, false
+				},
+			)
+		},
+	)
+}
+
+// SkipLast returns a new Finisher that skips the last n elements.
+// Elements are delayed by a ring buffer of n elements, so only n elements are held in memory at once.
+func (fin Finisher) SkipLast(n uint) Finisher {
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				ring  = make([]interface{}, n)
+				next  uint
+				count uint
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					for it.Next() {
+						if n == 0 {
+							return it.Value(), true
+						}
+
+						// Replace the oldest element with the newest, and return the oldest once the ring is full
+						oldest := ring[next]
+						ring[next] = it.Value()
+						next = (next + 1) % n
+
+						if count == n {
+							return oldest, true
+						}
+						count++
+					}
+
+					return nil, false
+				},
+			)
+		},
+	)
+}
+
+// TakeLast returns a new Finisher that only iterates the last n elements, ignoring the rest.
+// All elements are read before the first is returned, but only the last n are held in memory, using a ring buffer.
+func (fin Finisher) TakeLast(n uint) Finisher {
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				ring  = make([]interface{}, n)
+				next  uint
+				count uint
+				done  bool
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !done {
+						done = true
+
+						// Keep the last n elements, overwriting the oldest as needed
+						for it.Next() {
+							if n > 0 {
+								ring[next] = it.Value()
+								next = (next + 1) % n
+								if count < n {
+									count++
+								}
+							}
+						}
+
+						// The oldest element is at next if the ring is full, otherwise at index 0
+						if count < n {
+							next = 0
+						}
+					}
+
+					if count == 0 {
+						return nil, false
+					}
+
+					val := ring[next]
+					ring[next] = nil
+					next = (next + 1) % n
+					count--
+
+					return val, true
+				},
+			)
+		},
+	)
+}
+
+// Limit returns a new stream that only iterates the first n elements, ignoring the rest
+// If the Finsher is infinite, calling this method marks the finisher is finite.
+func (fin Finisher) Limit(n uint) Finisher {
+	newFin := fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var elementsRead uint
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if (elementsRead == n) || (!it.Next()) {
+						return nil, false
+					}
+
+					elementsRead++
+					return it.Value(), true
+				},
+			)
+		},
+	)
+
+	// Mark new Finisher as finite now that we have a limit
+	newFin.finite = true
+	return newFin
+}
+
+// TakeWhile returns a new stream that iterates elements until the first element that does not pass the given predicate,
+// ignoring that element and the rest.
+// If the Finisher is infinite, calling this method marks the Finisher as finite, since it is assumed that some element will not pass.
+func (fin Finisher) TakeWhile(f func(element interface{}) bool) Finisher {
+	newFin := fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			done := false
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if done || (!it.Next()) {
+						return nil, false
+					}
+
+					if val := it.Value(); f(val) {
+						return val, true
+					}
+
+					done = true
+					return nil, false
+				},
+			)
+		},
+	)
+
+	// Mark new Finisher as finite now that we have a condition to stop
+	newFin.finite = true
+	return newFin
+}
+
+// TakeUntil returns a new Finisher that iterates elements until the done channel is closed or receives a value, so that
+// a Stream of a live source can be stopped by an external event.
+// The channel is checked before reading each element, so an element that is already being read when the signal occurs
+// is still returned.
+// If the Finisher is infinite, calling this method marks the Finisher as finite, since it is assumed that the signal will occur.
+func (fin Finisher) TakeUntil(done <-chan struct{}) Finisher {
+	newFin := fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			stopped := false
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !stopped {
+						select {
+						case <-done:
+							stopped = true
+						default:
+						}
+					}
+
+					if stopped || (!it.Next()) {
+						return nil, false
+					}
+
+					return it.Value(), true
+				},
+			)
+		},
+	)
+
+	// Mark new Finisher as finite now that we have a signal to stop
+	newFin.finite = true
+	return newFin
+}
+
+// PeekIndexed returns a new Finisher that calls a function with the index and value of each element, for debugging.
+// If the function panics with an error, the error is rethrown as is, so that callers can still examine it. Any other
+// panic value is rethrown as a string formatted by ErrPeekPanic with the label and index.
+func (fin Finisher) PeekIndexed(label string, f func(index int, element interface{})) Finisher {
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			index := 0
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !it.Next() {
+						return nil, false
+					}
+
+					val := it.Value()
+					func() {
+						defer func() {
+							if err := recover(); err != nil {
+								if _, isErr := err.(error); isErr {
+									panic(err)
+								}
+
+								panic(fmt.Sprintf(ErrPeekPanic, label, index, err))
+							}
+						}()
+
+						f(index, val)
+					}()
+
+					index++
+					return val, true
+				},
+			)
+		},
+	)
+}
+
+// peekWhen returns a new Finisher that calls f with each element whose index passes the given predicate
+func (fin Finisher) peekWhen(when func(index int) bool, f func(element interface{})) Finisher {
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			index := 0
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !it.Next() {
+						return nil, false
+					}
+
+					val := it.Value()
+					if when(index) {
+						f(val)
+					}
+
+					index++
+					return val, true
 				},
 			)
 		},
 	)
 }
 
-// Limit returns a new stream that only iterates the first n elements, ignoring the rest
-// If the Finsher is infinite, calling this method marks the finisher is finite.
-func (fin Finisher) Limit(n uint) Finisher {
-	var (
-		elementsRead uint
+// PeekEvery returns a new Finisher that calls a function with the first element and every nth element after it, for
+// debugging hot pipelines without paying the cost of the function for every element.
+// Panics if n < 1.
+func (fin Finisher) PeekEvery(n int, f func(element interface{})) Finisher {
+	if n < 1 {
+		panic("n must be > 0")
+	}
+
+	return fin.peekWhen(
+		func(index int) bool {
+			return index%n == 0
+		},
+		f,
 	)
+}
 
-	newFin := fin.Transform(
+// PeekSample returns a new Finisher that calls a function with a random sample of elements, where each element is
+// sampled with the given probability, for debugging hot pipelines without paying the cost of the function for every
+// element. The sample is chosen using rnd, or the default source of math/rand if rnd is nil.
+// Panics if rate is not >= 0 and <= 1.
+func (fin Finisher) PeekSample(rate float64, rnd *rand.Rand, f func(element interface{})) Finisher {
+	if (rate < 0) || (rate > 1) {
+		panic("rate must be >= 0 and <= 1")
+	}
+
+	random := rand.Float64
+	if rnd != nil {
+		random = rnd.Float64
+	}
+
+	return fin.peekWhen(
+		func(int) bool {
+			return random() < rate
+		},
+		f,
+	)
+}
+
+// WithProgress returns a new Finisher that calls report with the number of elements processed so far and the time
+// elapsed since the first element was requested, after every nth element, so that long running terminals can report
+// progress. The count only includes elements that reach this point of the Finisher.
+// Panics if every < 1.
+func (fin Finisher) WithProgress(every int, report func(processed int64, elapsed time.Duration)) Finisher {
+	if every < 1 {
+		panic("every must be > 0")
+	}
+
+	return fin.Transform(
 		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				processed int64
+				start     time.Time
+			)
+
 			return goiter.NewIter(
 				func() (interface{}, bool) {
-					if (elementsRead == n) || (!it.Next()) {
+					if start.IsZero() {
+						start = time.Now()
+					}
+
+					if !it.Next() {
 						return nil, false
 					}
 
-					elementsRead++
+					processed++
+					if processed%int64(every) == 0 {
+						report(processed, time.Since(start))
+					}
+
 					return it.Value(), true
 				},
 			)
 		},
 	)
-
-	// Mark new Finisher as finite now that we have a limit
-	newFin.finite = true
-	return newFin
 }
 
 // Sorted returns a new stream with the values sorted by the provided comparator.
@@ -535,17 +2289,98 @@ func (fin Finisher) ReverseSorted(less func(element1, element2 interface{}) bool
 	})
 }
 
+// SortedNatural returns a new stream with the values sorted in their natural order, which is chosen by the type of the
+// elements: numeric order for all int kinds, all uint kinds, all float kinds, *big.Int, *big.Rat, and *big.Float,
+// chronological order for time.Time, and lexicographic order for string kinds.
+// Elements of different kinds in the same category, such as int and int64, are compared with each other.
+// Panics when the elements are sorted with a message formatted by ErrNaturalOrder, if any two elements are not in the
+// same category.
+func (fin Finisher) SortedNatural() Finisher {
+	return fin.Sorted(naturalLess)
+}
+
+// naturalLess is the less function of SortedNatural
+func naturalLess(element1, element2 interface{}) bool {
+	switch e1 := element1.(type) {
+	case time.Time:
+		if e2, ok := element2.(time.Time); ok {
+			return e1.Before(e2)
+		}
+
+	case *big.Int:
+		if e2, ok := element2.(*big.Int); ok {
+			return e1.Cmp(e2) < 0
+		}
+
+	case *big.Rat:
+		if e2, ok := element2.(*big.Rat); ok {
+			return e1.Cmp(e2) < 0
+		}
+
+	case *big.Float:
+		if e2, ok := element2.(*big.Float); ok {
+			return e1.Cmp(e2) < 0
+		}
+
+	default:
+		var (
+			v1 = reflect.ValueOf(element1)
+			v2 = reflect.ValueOf(element2)
+		)
+
+		if v1.IsValid() && v2.IsValid() {
+			switch k1, k2 := naturalKind(v1.Kind()), naturalKind(v2.Kind()); {
+			case k1 != k2:
+			case k1 == reflect.Int:
+				return v1.Int() < v2.Int()
+			case k1 == reflect.Uint:
+				return v1.Uint() < v2.Uint()
+			case k1 == reflect.Float64:
+				return v1.Float() < v2.Float()
+			case k1 == reflect.String:
+				return v1.String() < v2.String()
+			}
+		}
+	}
+
+	panic(fmt.Sprintf(ErrNaturalOrder, element1, element2))
+}
+
+// naturalKind returns the category of a kind for natural ordering: reflect.Int for all int kinds, reflect.Uint for
+// all uint kinds, reflect.Float64 for all float kinds, reflect.String for strings, and reflect.Invalid otherwise
+func naturalKind(kind reflect.Kind) reflect.Kind {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	case reflect.String:
+		return reflect.String
+	}
+
+	return reflect.Invalid
+}
+
 // ==== Terminals
 
 // Iter returns an iterator of the elements in this Finisher.
 // Note that a Finisher can only be iterated once by a single *goiter.Iter instance.
+// The underlying Stream is closed when the iterator is exhausted.
 // Panics if the Finisher is infinite.
 func (fin Finisher) Iter() *goiter.Iter {
 	fin.panicIfInfinite()
 
-	it := fin.source.Iter()
-	if fin.transform != nil {
-		it = fin.transform(it)
+	return fin.iter()
+}
+
+// iter is Iter without the check for an infinite Finisher.
+// If FindFirst has read part of an iteration, the result reads the remaining elements of it.
+func (fin Finisher) iter() *goiter.Iter {
+	it := fin.partial.it
+	if (it == nil) || fin.partial.finished {
+		it = fin.pipeline()
 	}
 
 	return goiter.NewIter(
@@ -554,16 +2389,26 @@ func (fin Finisher) Iter() *goiter.Iter {
 				return it.Value(), true
 			}
 
+			fin.source.Close()
 			return nil, false
 		},
 	)
 }
 
+// Close closes the underlying Stream, see Stream.Close.
+// Only required when an iterator returned by Iter is not exhausted.
+func (fin Finisher) Close() error {
+	return fin.source.Close()
+}
+
 // AllMatch is true if the predicate matches all elements with short-circuit logic.
 // Panics if the Finisher is infinite.
 func (fin Finisher) AllMatch(f func(element interface{}) bool) bool {
+	term := fin.terminal("AllMatch")
+	defer term.done()
+
 	allMatch := true
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		if allMatch = f(it.Value()); !allMatch {
 			break
 		}
@@ -575,8 +2420,11 @@ func (fin Finisher) AllMatch(f func(element interface{}) bool) bool {
 // AnyMatch is true if the predicate matches any element with short-circuit logic.
 // Panics if the Finisher is infinite.
 func (fin Finisher) AnyMatch(f func(element interface{}) bool) bool {
+	term := fin.terminal("AnyMatch")
+	defer term.done()
+
 	anyMatch := false
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		if anyMatch = f(it.Value()); anyMatch {
 			break
 		}
@@ -588,8 +2436,11 @@ func (fin Finisher) AnyMatch(f func(element interface{}) bool) bool {
 // NoneMatch is true if the predicate matches none of the elements with short-circuit logic.
 // Panics if the Finisher is infinite.
 func (fin Finisher) NoneMatch(f func(element interface{}) bool) bool {
+	term := fin.terminal("NoneMatch")
+	defer term.done()
+
 	noneMatch := true
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		if noneMatch = !f(it.Value()); !noneMatch {
 			break
 		}
@@ -598,16 +2449,114 @@ func (fin Finisher) NoneMatch(f func(element interface{}) bool) bool {
 	return noneMatch
 }
 
+// firstMatch returns the index of the first element that passes the given predicate, which receives the index and
+// element, and true, or -1 and false if no element passes, with short-circuit logic
+func (fin Finisher) firstMatch(name string, f func(index int, element interface{}) bool) (int, bool) {
+	term := fin.terminal(name)
+	defer term.done()
+
+	for i, it := 0, term.Iter(); it.Next(); i++ {
+		if f(i, it.Value()) {
+			return i, true
+		}
+	}
+
+	return -1, false
+}
+
+// AllMatchIndexed is like AllMatch, except that the predicate also receives the index of each element.
+// Panics if the Finisher is infinite.
+func (fin Finisher) AllMatchIndexed(f func(index int, element interface{}) bool) bool {
+	_, found := fin.firstMatch(
+		"AllMatchIndexed",
+		func(index int, element interface{}) bool {
+			return !f(index, element)
+		},
+	)
+
+	return !found
+}
+
+// AnyMatchIndexed is like AnyMatch, except that the predicate also receives the index of each element.
+// Panics if the Finisher is infinite.
+func (fin Finisher) AnyMatchIndexed(f func(index int, element interface{}) bool) bool {
+	_, found := fin.firstMatch("AnyMatchIndexed", f)
+	return found
+}
+
+// NoneMatchIndexed is like NoneMatch, except that the predicate also receives the index of each element.
+// Panics if the Finisher is infinite.
+func (fin Finisher) NoneMatchIndexed(f func(index int, element interface{}) bool) bool {
+	_, found := fin.firstMatch("NoneMatchIndexed", f)
+	return !found
+}
+
+// AllDistinct is true if no two elements are equal, with short-circuit logic that stops at the first duplicate.
+// If the optional key function is provided, elements are compared by the key returned for each element.
+// Elements or keys that cannot be map keys, such as slices, are compared with reflect.DeepEqual, the same as Distinct.
+// Panics if the Finisher is infinite.
+func (fin Finisher) AllDistinct(key ...func(element interface{}) (key interface{})) bool {
+	term := fin.terminal("AllDistinct")
+	defer term.done()
+
+	notRead := distinctFilter(nil)
+	for it := term.Iter(); it.Next(); {
+		k := it.Value()
+		if len(key) > 0 {
+			k = key[0](k)
+		}
+
+		if !notRead(k) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IndexOf returns the position of the first element that passes the given predicate, or -1 if no element passes, with
+// short-circuit logic.
+// Panics if the Finisher is infinite.
+func (fin Finisher) IndexOf(f func(element interface{}) bool) int {
+	index, _ := fin.firstMatch(
+		"IndexOf",
+		func(_ int, element interface{}) bool {
+			return f(element)
+		},
+	)
+
+	return index
+}
+
+// LastIndexOf returns the position of the last element that passes the given predicate, or -1 if no element passes.
+// Panics if the Finisher is infinite.
+func (fin Finisher) LastIndexOf(f func(element interface{}) bool) int {
+	term := fin.terminal("LastIndexOf")
+	defer term.done()
+
+	index := -1
+	for i, it := 0, term.Iter(); it.Next(); i++ {
+		if f(it.Value()) {
+			index = i
+		}
+	}
+
+	return index
+}
+
 // Average returns an optional average value.
 // The slice elements must be convertible to a float64.
 // Panics if the Finisher is infinite.
 func (fin Finisher) Average() gooptional.Optional {
+	term := fin.terminal("Average")
+	defer term.done()
+
 	var (
 		sum   float64
 		count int
 	)
 
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		sum += it.Float64Value()
 		count++
 	}
@@ -624,12 +2573,15 @@ func (fin Finisher) Average() gooptional.Optional {
 // The slice elements must be convertible to a float64.
 // Panics if the Finisher is infinite.
 func (fin Finisher) Sum() gooptional.Optional {
+	term := fin.terminal("Sum")
+	defer term.done()
+
 	var (
 		sum    float64
 		hasSum bool
 	)
 
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		sum += it.Float64Value()
 		hasSum = true
 	}
@@ -644,8 +2596,11 @@ func (fin Finisher) Sum() gooptional.Optional {
 // Count returns the count of all elements.
 // Panics if the Finisher is infinite.
 func (fin Finisher) Count() int {
+	term := fin.terminal("Count")
+	defer term.done()
+
 	count := 0
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		count++
 	}
 
@@ -655,8 +2610,11 @@ func (fin Finisher) Count() int {
 // Last returns the optional last element.
 // Panics if the Finisher is infinite.
 func (fin Finisher) Last() gooptional.Optional {
+	term := fin.terminal("Last")
+	defer term.done()
+
 	var last interface{}
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		last = it.Value()
 	}
 
@@ -666,8 +2624,11 @@ func (fin Finisher) Last() gooptional.Optional {
 // Max returns an optional maximum value according to the provided comparator.
 // Panics if the Finisher is infinite.
 func (fin Finisher) Max(less func(element1, element2 interface{}) bool) gooptional.Optional {
+	term := fin.terminal("Max")
+	defer term.done()
+
 	var max interface{}
-	if it := fin.Iter(); it.Next() {
+	if it := term.Iter(); it.Next() {
 		max = it.Value()
 
 		for it.Next() {
@@ -685,8 +2646,11 @@ func (fin Finisher) Max(less func(element1, element2 interface{}) bool) gooption
 // Min returns an optional minimum value according to the provided comparator.
 // Panics if the Finisher is infinite.
 func (fin Finisher) Min(less func(element1, element2 interface{}) bool) gooptional.Optional {
+	term := fin.terminal("Min")
+	defer term.done()
+
 	var min interface{}
-	if it := fin.Iter(); it.Next() {
+	if it := term.Iter(); it.Next() {
 		min = it.Value()
 
 		for it.Next() {
@@ -704,7 +2668,10 @@ func (fin Finisher) Min(less func(element1, element2 interface{}) bool) gooption
 // ForEach invokes a consumer with each element of the stream.
 // Panics if the Finisher is infinite.
 func (fin Finisher) ForEach(f func(element interface{})) {
-	for it := fin.Iter(); it.Next(); {
+	term := fin.terminal("ForEach")
+	defer term.done()
+
+	for it := term.Iter(); it.Next(); {
 		f(it.Value())
 	}
 }
@@ -720,8 +2687,11 @@ func (fin Finisher) Reduce(
 	identity interface{},
 	f func(accumulator interface{}, element2 interface{}) interface{},
 ) interface{} {
+	term := fin.terminal("Reduce")
+	defer term.done()
+
 	result := identity
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		result = f(result, it.Value())
 	}
 
@@ -746,13 +2716,150 @@ func (fin Finisher) GroupBy(f func(element interface{}) (key interface{})) map[i
 	return m
 }
 
+// GroupByOf is like GroupBy, except that the map key type and slice element type are the same as the types of aKey and
+// aValue.
+// EG, if aKey is a string and aValue is an Order, then a map[string][]Order is returned.
+// Panics if keys are not convertible to the key type or elements are not convertible to the element type.
+// Panics if the Finisher is infinite.
+func (fin Finisher) GroupByOf(f func(element interface{}) (key interface{}), aKey, aValue interface{}) interface{} {
+	term := fin.terminal("GroupByOf")
+	defer term.done()
+
+	var (
+		ktyp = reflect.TypeOf(aKey)
+		vtyp = reflect.TypeOf(aValue)
+		m    = reflect.MakeMap(reflect.MapOf(ktyp, reflect.SliceOf(vtyp)))
+	)
+
+	for it := term.Iter(); it.Next(); {
+		element := it.Value()
+		k := reflect.ValueOf(f(element)).Convert(ktyp)
+
+		group := m.MapIndex(k)
+		if !group.IsValid() {
+			group = reflect.MakeSlice(reflect.SliceOf(vtyp), 0, 1)
+		}
+
+		m.SetMapIndex(k, reflect.Append(group, reflect.ValueOf(element).Convert(vtyp)))
+	}
+
+	return m.Interface()
+}
+
+// ReduceByKey reduces elements per key in a single pass, without collecting the elements of each key like GroupBy does.
+// The given key function is executed on each element to get a key, and the resulting map contains
+// f(f(identity, element1), element2)... for the elements that have each key.
+// Since the identity is shared by all keys, it should be an immutable value such as a number or string.
+// Use AggregateByKey for an accumulator that is mutated, such as a slice or map.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ReduceByKey(
+	key func(element interface{}) interface{},
+	identity interface{},
+	f func(accumulator interface{}, element interface{}) interface{},
+) map[interface{}]interface{} {
+	return fin.AggregateByKey(
+		key,
+		func() interface{} {
+			return identity
+		},
+		f,
+	)
+}
+
+// AggregateByKey is like ReduceByKey, except that the identity is provided by a function that is called once for each key,
+// so that each key can have a separate mutable accumulator.
+// Panics if the Finisher is infinite.
+func (fin Finisher) AggregateByKey(
+	key func(element interface{}) interface{},
+	identity func() interface{},
+	f func(accumulator interface{}, element interface{}) interface{},
+) map[interface{}]interface{} {
+	term := fin.terminal("AggregateByKey")
+	defer term.done()
+
+	m := map[interface{}]interface{}{}
+
+	for it := term.Iter(); it.Next(); {
+		var (
+			element     = it.Value()
+			k           = key(element)
+			accumulator interface{}
+			haveKey     bool
+		)
+
+		if accumulator, haveKey = m[k]; !haveKey {
+			accumulator = identity()
+		}
+
+		m[k] = f(accumulator, element)
+	}
+
+	return m
+}
+
+// GroupByStream is like GroupBy, except that it returns a Stream of Group, so that the groups can be further
+// filtered, sorted, limited, or aggregated.
+// The groups are in the order that each key first occurs in, and the elements of each group are in stream order.
+// No elements are read until the first group is read.
+// Panics if the Finisher is infinite.
+func (fin Finisher) GroupByStream(f func(element interface{}) (key interface{})) Stream {
+	fin.panicIfInfinite()
+
+	var groupsIter *goiter.Iter
+
+	return construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				if groupsIter == nil {
+					term := fin.terminal("GroupByStream")
+					defer term.done()
+
+					// Group all elements, tracking the order keys first occur in
+					var (
+						keys []interface{}
+						m    = map[interface{}][]interface{}{}
+					)
+
+					for it := term.Iter(); it.Next(); {
+						element := it.Value()
+						k := f(element)
+						if _, haveKey := m[k]; !haveKey {
+							keys = append(keys, k)
+						}
+
+						m[k] = append(m[k], element)
+					}
+
+					groups := make([]Group, len(keys))
+					for i, k := range keys {
+						groups[i] = Group{Key: k, Elements: Of(m[k]...)}
+					}
+
+					groupsIter = goiter.OfElements(groups)
+				}
+
+				// Return next group
+				if groupsIter.Next() {
+					return groupsIter.Value(), true
+				}
+
+				return nil, false
+			},
+		),
+		true,
+	)
+}
+
 // ToMap returns a map of all elements by invoking the given function to get a key/value pair for the map.
 // It is up to the function to generate unique keys to prevent values from being overwritten.
 // Panics if the Finisher is infinite.
 func (fin Finisher) ToMap(f func(interface{}) (key interface{}, value interface{})) map[interface{}]interface{} {
+	term := fin.terminal("ToMap")
+	defer term.done()
+
 	m := map[interface{}]interface{}{}
 
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		k, v := f(it.Value())
 		m[k] = v
 	}
@@ -768,13 +2875,16 @@ func (fin Finisher) ToMapOf(
 	f func(interface{}) (key interface{}, value interface{}),
 	aKey, aValue interface{},
 ) interface{} {
+	term := fin.terminal("ToMapOf")
+	defer term.done()
+
 	var (
 		ktyp = reflect.TypeOf(aKey)
 		vtyp = reflect.TypeOf(aValue)
 		m    = reflect.MakeMap(reflect.MapOf(ktyp, vtyp))
 	)
 
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		k, v := f(it.Value())
 		m.SetMapIndex(
 			reflect.ValueOf(k).Convert(ktyp),
@@ -788,9 +2898,12 @@ func (fin Finisher) ToMapOf(
 // ToSlice returns a slice of all elements.
 // Panics if the Finisher is infinite.
 func (fin Finisher) ToSlice() []interface{} {
+	term := fin.terminal("ToSlice")
+	defer term.done()
+
 	array := []interface{}{}
 
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		array = append(array, it.Value())
 	}
 
@@ -802,55 +2915,152 @@ func (fin Finisher) ToSlice() []interface{} {
 // Panics if elements are not convertible to the type of elementVal.
 // Panics if the Finisher is infinite.
 func (fin Finisher) ToSliceOf(elementVal interface{}) interface{} {
+	term := fin.terminal("ToSliceOf")
+	defer term.done()
+
 	var (
 		elementTyp = reflect.TypeOf(elementVal)
 		array      = reflect.MakeSlice(reflect.SliceOf(elementTyp), 0, 0)
 	)
 
-	for it := fin.Iter(); it.Next(); {
+	for it := term.Iter(); it.Next(); {
 		array = reflect.Append(array, reflect.ValueOf(it.Value()).Convert(elementTyp))
 	}
 
 	return array.Interface()
 }
 
-// ToStream returns a stream of all elements.
+// FillSlice writes up to len(dest) elements into dest, which must be a slice or a pointer to an array, converting each
+// element to the element type of dest. No more than len(dest) elements are read, and the Stream is closed afterwards.
+// Returns the number of elements written, and the first error converting an element or closing the Stream, in which
+// case no further elements are read.
+// Panics if dest is not a slice or pointer to an array.
+// Panics if the Finisher is infinite.
+func (fin Finisher) FillSlice(dest interface{}) (n int, err error) {
+	array := reflect.ValueOf(dest)
+	if (array.Kind() == reflect.Ptr) && (array.Elem().Kind() == reflect.Array) {
+		array = array.Elem()
+	} else if array.Kind() != reflect.Slice {
+		panic("dest must be a slice or pointer to an array")
+	}
+
+	term := fin.terminal("FillSlice")
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var (
+		elementTyp = array.Type().Elem()
+		size       = array.Len()
+	)
+
+	for it := term.Iter(); (n < size) && it.Next(); n++ {
+		val, convErr := convertTo(it.Value(), elementTyp)
+		if convErr != nil {
+			err = fmt.Errorf("element %d: %w", n, convErr)
+			return n, err
+		}
+
+		array.Index(n).Set(val)
+	}
+
+	return n, err
+}
+
+// ToSortedSliceOf is like ToSliceOf, except that the slice is sorted in place by the given function, which receives
+// elements that have been converted to the type of elementVal.
+// This avoids buffering the elements twice, as Sorted followed by ToSliceOf does.
+// Panics if elements are not convertible to the type of elementVal.
 // Panics if the Finisher is infinite.
+func (fin Finisher) ToSortedSliceOf(elementVal interface{}, less func(element1, element2 interface{}) bool) interface{} {
+	term := fin.terminal("ToSortedSliceOf")
+	defer term.done()
+
+	var (
+		elementTyp = reflect.TypeOf(elementVal)
+		array      = reflect.MakeSlice(reflect.SliceOf(elementTyp), 0, 0)
+	)
+
+	for it := term.Iter(); it.Next(); {
+		array = reflect.Append(array, reflect.ValueOf(it.Value()).Convert(elementTyp))
+	}
+
+	sort.Slice(array.Interface(), func(i, j int) bool {
+		return less(array.Index(i).Interface(), array.Index(j).Interface())
+	})
+
+	return array.Interface()
+}
+
+// ToStream returns a Stream of the elements of this Finisher, so that further per element transforms can be applied.
+// The elements are read lazily as the result is iterated, and the result is infinite if this Finisher is infinite.
+// Closing the result closes this Finisher.
+// Use ToMaterializedStream to read all elements first.
 func (fin Finisher) ToStream() Stream {
+	var it *goiter.Iter
+
+	result := construct(
+		goiter.NewIter(
+			func() (interface{}, bool) {
+				if it == nil {
+					it = fin.iter()
+				}
+
+				if it.Next() {
+					return it.Value(), true
+				}
+
+				return nil, false
+			},
+		),
+		fin.finite,
+	).OnClose(fin.Close)
+	result.metrics = fin.source.metrics
+
+	return result
+}
+
+// ToMaterializedStream returns a Stream of the elements of this Finisher, which are all read into memory first, so that
+// this Finisher is closed before the result is returned.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ToMaterializedStream() Stream {
 	return Of(fin.ToSlice()...)
 }
 
 // ==== Parallel processing
 
-// ParallelToStream processes the result of the current Finisher in parallel using a number of goroutines.
-// The number of items provided is interpreted according to the optional ParallelFlags value:
-// 1. NumberOfGoroutines - numItems indicates the number of go routines (default)
-// 2. NumberOfItemsPerGoroutine - numItems indicates the number of items each go routine processes
-// Either way, the results are ordered, and a new Stream is returned that iterates them.
-// If numItems is 0, it defaults to DefaultNumberOfParallelItems.
+// Parallel processes the result of the current Finisher in parallel without any tuning, and returns a new Stream that
+// iterates the ordered results.
+// The number of goroutines is runtime.GOMAXPROCS. The first few elements are transformed sequentially to measure the cost
+// per element, and the rest are only processed by goroutines if they are estimated to take long enough to benefit.
+// The goroutines use WorkStealing, with the number of items claimed at a time chosen from the measured cost.
 // Panics if the Finisher is infinite.
-func (fin Finisher) ParallelToStream(numItems uint, flag ...ParallelFlags) Stream {
-	fin.panicIfInfinite()
+func (fin Finisher) Parallel() Stream {
+	term := fin.terminal("Parallel")
+	defer term.done()
 
-	theFlag := NumberOfGoroutines
-	if len(flag) > 0 {
-		theFlag = flag[0]
-	}
+	fin.panicIfInfinite()
 
-	data := doParallel(
-		fin.source.source,
+	data := doParallelAdaptive(
+		fin.source.source(),
 		fin.source.transform,
 		fin.transform,
-		numItems,
-		theFlag,
+		runtime.GOMAXPROCS(0),
 	)
+	term.elements = uint64(len(data))
 
 	return Of(data...)
 }
 
-// ParallelToSlice is the same as Parallel, except that it returns the data as a slice.
+// parallel is the execution path shared by the parallel terminals, which starts a terminal of the given name, and applies
+// the Stream transforms in parallel to the raw source, followed by the Finisher transforms sequentially.
 // Panics if the Finisher is infinite.
-func (fin Finisher) ParallelToSlice(numItems uint, flag ...ParallelFlags) []interface{} {
+func (fin Finisher) parallel(name string, numItems uint, flag []ParallelFlags) []interface{} {
+	term := fin.terminal(name)
+	defer term.done()
+
 	fin.panicIfInfinite()
 
 	theFlag := NumberOfGoroutines
@@ -859,33 +3069,37 @@ func (fin Finisher) ParallelToSlice(numItems uint, flag ...ParallelFlags) []inte
 	}
 
 	data := doParallel(
-		fin.source.Iter(),
-		fin.transform,
+		fin.source.source(),
+		fin.source.transform,
 		fin.transform,
 		numItems,
 		theFlag,
 	)
+	term.elements = uint64(len(data))
 
 	return data
 }
 
-// ParallelToSliceOf is the same as ParallelSlice, except that it returns the data as a slice whose type matches the element value given.
+// ParallelToStream processes the result of the current Finisher in parallel using a number of goroutines.
+// The number of items provided is interpreted according to the optional ParallelFlags value:
+// 1. NumberOfGoroutines - numItems indicates the number of go routines (default)
+// 2. NumberOfItemsPerGoroutine - numItems indicates the number of items each go routine processes
+// 3. WorkStealing - numItems indicates the number of items each go routine claims at a time from a shared queue
+// In all cases, the results are ordered, and a new Stream is returned that iterates them.
+// If numItems is 0, it defaults to DefaultNumberOfParallelItems.
 // Panics if the Finisher is infinite.
-func (fin Finisher) ParallelToSliceOf(elementValue interface{}, numItems uint, flag ...ParallelFlags) interface{} {
-	fin.panicIfInfinite()
-
-	theFlag := NumberOfGoroutines
-	if len(flag) > 0 {
-		theFlag = flag[0]
-	}
+func (fin Finisher) ParallelToStream(numItems uint, flag ...ParallelFlags) Stream {
+	return Of(fin.parallel("ParallelToStream", numItems, flag)...)
+}
 
-	data := doParallel(
-		fin.source.source,
-		fin.source.transform,
-		fin.transform,
-		numItems,
-		theFlag,
-	)
+// ParallelToSlice is the same as Parallel, except that it returns the data as a slice.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ParallelToSlice(numItems uint, flag ...ParallelFlags) []interface{} {
+	return fin.parallel("ParallelToSlice", numItems, flag)
+}
 
-	return goiter.FlattenArraySliceAsType(data, elementValue)
+// ParallelToSliceOf is the same as ParallelSlice, except that it returns the data as a slice whose type matches the element value given.
+// Panics if the Finisher is infinite.
+func (fin Finisher) ParallelToSliceOf(elementValue interface{}, numItems uint, flag ...ParallelFlags) interface{} {
+	return goiter.FlattenArraySliceAsType(fin.parallel("ParallelToSliceOf", numItems, flag), elementValue)
 }
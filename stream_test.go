@@ -3,13 +3,18 @@
 package gostream
 
 import (
-	//	"fmt"
+	"errors"
+	"fmt"
 	"math/big"
+	"math/rand"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/bantling/gofuncs"
 	"github.com/bantling/goiter"
+	"github.com/bantling/gooptional"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,6 +30,73 @@ func TestOfIterables(t *testing.T) {
 	assert.Equal(t, []interface{}{6, 5, 4}, s.AndThen().ToSlice())
 }
 
+func TestOfOptionals(t *testing.T) {
+	assert.Equal(t, []interface{}{}, OfOptionals().AndThen().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{1, 3},
+		OfOptionals(gooptional.Of(1), gooptional.Of(), gooptional.Of(3)).AndThen().ToSlice(),
+	)
+}
+
+func TestZipSlices(t *testing.T) {
+	toSlice := func(values ...interface{}) interface{} { return values }
+
+	assert.Equal(t, []interface{}{}, ZipSlices(toSlice).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{}, ZipSlices(toSlice, []int{}).AndThen().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{[]interface{}{1, "a", true}, []interface{}{2, "b", false}},
+		ZipSlices(toSlice, []int{1, 2, 3}, [2]string{"a", "b"}, []bool{true, false}).AndThen().ToSlice(),
+	)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "slices[1] must be a slice or array", recover())
+		}()
+
+		ZipSlices(toSlice, []int{}, 1)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestSubsets(t *testing.T) {
+	assert.Equal(t, []interface{}{[]interface{}{}}, Subsets(nil).AndThen().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{
+			[]interface{}{},
+			[]interface{}{1},
+			[]interface{}{2},
+			[]interface{}{3},
+			[]interface{}{1, 2},
+			[]interface{}{1, 3},
+			[]interface{}{2, 3},
+			[]interface{}{1, 2, 3},
+		},
+		Subsets([]interface{}{1, 2, 3}).AndThen().ToSlice(),
+	)
+
+	// Size
+	assert.Equal(
+		t,
+		[]interface{}{[]interface{}{1, 2}, []interface{}{1, 3}, []interface{}{2, 3}},
+		Subsets([]interface{}{1, 2, 3}, 2).AndThen().ToSlice(),
+	)
+	assert.Equal(t, []interface{}{[]interface{}{}}, Subsets([]interface{}{1, 2}, 0).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{}, Subsets([]interface{}{1, 2}, 3).AndThen().ToSlice())
+	assert.Equal(t, 1<<10, Subsets(make([]interface{}, 10)).AndThen().Count())
+
+	func() {
+		defer func() {
+			assert.Equal(t, "size must be >= 0", recover())
+		}()
+
+		Subsets(nil, -1)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
 func TestStreamIterate(t *testing.T) {
 	fn := func(element interface{}) interface{} {
 		return element.(int) * 2
@@ -78,6 +150,15 @@ func TestStreamIsIterable(t *testing.T) {
 
 // ==== Transforms
 
+func TestStreamFlattenOptionals(t *testing.T) {
+	assert.Equal(t, []interface{}{}, Of().AndThen().FlattenOptionals().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{1, 3},
+		Of(gooptional.Of(), gooptional.Of(1), gooptional.Of(), gooptional.Of(3)).AndThen().FlattenOptionals().ToSlice(),
+	)
+}
+
 func TestStreamDistinct(t *testing.T) {
 	s := Of()
 	assert.Equal(t, []interface{}{}, s.AndThen().Distinct().ToSlice())
@@ -122,9 +203,171 @@ func TestStreamFilterNot(t *testing.T) {
 	assert.Equal(t, []interface{}{3}, s.FilterNot(fn).AndThen().ToSlice())
 }
 
+func TestStreamFilterMatching(t *testing.T) {
+	var (
+		lessThan3 = func(element interface{}) bool { return element.(int) < 3 }
+		even      = func(element interface{}) bool { return element.(int)%2 == 0 }
+	)
+
+	assert.Equal(t, []interface{}{2}, Of(1, 2, 3, 4).FilterMatching(MatchAll, lessThan3, even).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 4}, Of(1, 2, 3, 4).FilterMatching(MatchAny, lessThan3, even).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, Of(1, 2).FilterMatching(MatchAll).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{}, Of(1, 2).FilterMatching(MatchAny).AndThen().ToSlice())
+
+	assert.Equal(t, []interface{}{2}, Of(1, 2, 3, 4).AndThen().FilterMatching(MatchAll, lessThan3, even).ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 4}, Of(1, 2, 3, 4).AndThen().FilterMatching(MatchAny, lessThan3, even).ToSlice())
+}
+
 func TestStreamLimit(t *testing.T) {
 	s := Of(1, 2, 3)
 	assert.Equal(t, []interface{}{1, 2}, s.AndThen().Limit(2).ToSlice())
+
+	// Stream.Limit
+	s = Of(1, 2, 3).Limit(0)
+	assert.Equal(t, []interface{}{}, s.AndThen().ToSlice())
+
+	s = Of(1, 2, 3).Limit(2)
+	assert.Equal(t, []interface{}{1, 2}, s.AndThen().ToSlice())
+
+	s = Of(1, 2, 3).Limit(4)
+	assert.Equal(t, []interface{}{1, 2, 3}, s.AndThen().ToSlice())
+
+	// Limit makes an infinite stream finite before the Finisher
+	fn := func(element interface{}) interface{} { return element.(int) + 1 }
+	s = Iterate(0, fn).Limit(4)
+	assert.Equal(t, []int{4, 3, 2, 1}, s.AndThen().ReverseSorted(gofuncs.IntSortFunc).ToSliceOf(0))
+
+	// Transforms after the limit can be executed in parallel
+	s = Iterate(0, fn).Limit(5).Map(gofuncs.Map(func(i int) int { return i * 2 }))
+	assert.Equal(t, []int{2, 4, 6, 8, 10}, s.AndThen().ParallelToSliceOf(0, 2))
+}
+
+func TestStreamPreSorted(t *testing.T) {
+	var (
+		less   = func(element1, element2 interface{}) bool { return element1.(int) < element2.(int) }
+		mapped []interface{}
+		double = func(element interface{}) interface{} {
+			mapped = append(mapped, element)
+			return element.(int) * 2
+		}
+	)
+
+	assert.Equal(t, []interface{}{}, Of().PreSorted(less).AndThen().ToSlice())
+
+	// Transforms after PreSorted see sorted elements
+	assert.Equal(t, []interface{}{2, 4, 6}, Of(3, 1, 2).PreSorted(less).Map(double).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, mapped)
+
+	// Transforms after PreSorted run in parallel on sorted elements
+	assert.Equal(
+		t,
+		[]interface{}{2, 4, 6, 8, 10},
+		Of(5, 3, 1, 4, 2).PreSorted(less).Map(func(element interface{}) interface{} { return element.(int) * 2 }).AndThen().ParallelToSlice(2),
+	)
+
+	// Each iteration of a replayable Stream sorts again
+	sorted := Of(3, 1, 2).Snapshot().PreSorted(less)
+	assert.Equal(t, []interface{}{1, 2, 3}, sorted.AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, sorted.AndThen().ToSlice())
+
+	func() {
+		defer func() {
+			assert.Equal(t, ErrInfiniteFinisher, recover())
+		}()
+
+		Iterate(0, func(element interface{}) interface{} { return element }).PreSorted(less)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamPreDistinct(t *testing.T) {
+	var (
+		mapped []interface{}
+		double = func(element interface{}) interface{} {
+			mapped = append(mapped, element)
+			return element.(int) * 2
+		}
+	)
+
+	assert.Equal(t, []interface{}{}, Of().PreDistinct().AndThen().ToSlice())
+
+	// Transforms after PreDistinct only see distinct elements
+	assert.Equal(t, []interface{}{2, 4, 6}, Of(1, 2, 1, 3, 2).PreDistinct().Map(double).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, mapped)
+
+	store := MapStateStore{}
+	assert.Equal(t, []interface{}{1, 2}, Of(1, 2, 1).PreDistinct(store).AndThen().ToSlice())
+	assert.Equal(t, MapStateStore{1: true, 2: true}, store)
+
+	// Each iteration of a replayable Stream starts with a new MapStateStore, while a given StateStore is kept
+	distinct := Of(1, 2, 1).Snapshot().PreDistinct()
+	assert.Equal(t, []interface{}{1, 2}, distinct.AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, distinct.AndThen().ToSlice())
+
+	distinct = Of(1, 2, 1).Snapshot().PreDistinct(MapStateStore{})
+	assert.Equal(t, []interface{}{1, 2}, distinct.AndThen().ToSlice())
+	assert.Equal(t, []interface{}{}, distinct.AndThen().ToSlice())
+
+	// Infinite
+	inc := func(element interface{}) interface{} { return element.(int) + 1 }
+	assert.Equal(
+		t,
+		[]interface{}{0, 1, 2},
+		Iterate(-1, inc).Map(func(element interface{}) interface{} { return element.(int) / 2 }).PreDistinct().Limit(3).AndThen().ToSlice(),
+	)
+}
+
+func TestStreamMapWhile(t *testing.T) {
+	halveEven := func(element interface{}) (interface{}, bool) {
+		i := element.(int)
+		return i / 2, i%2 == 0
+	}
+
+	assert.Equal(t, []interface{}{}, Of().MapWhile(halveEven).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, Of(2, 4).MapWhile(halveEven).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, Of(2, 4, 5, 6).MapWhile(halveEven).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{}, Of(1, 2).MapWhile(halveEven).AndThen().ToSlice())
+
+	// Infinite Stream becomes finite
+	assert.Equal(
+		t,
+		[]interface{}{2, 4, 6},
+		Iterate(0, func(element interface{}) interface{} { return element.(int) + 1 }).
+			MapWhile(func(element interface{}) (interface{}, bool) { return element.(int) * 2, element.(int) < 4 }).
+			AndThen().
+			ToSlice(),
+	)
+
+	// Parallel
+	assert.Equal(t, []interface{}{1, 2, 3}, Of(2, 4, 6, 7, 8).MapWhile(halveEven).AndThen().ParallelToSlice(2))
+
+	// Each iteration of a replayable Stream starts over
+	for _, s := range []Stream{Of(2, 4, 5).Cache().MapWhile(halveEven), Of(2, 4, 5).Snapshot().MapWhile(halveEven)} {
+		assert.Equal(t, []interface{}{1, 2}, s.AndThen().ToSlice())
+		assert.Equal(t, []interface{}{1, 2}, s.AndThen().ToSlice())
+	}
+}
+
+func TestStreamTakeWhile(t *testing.T) {
+	fn := func(element interface{}) bool { return element.(int) < 3 }
+	s := Of().TakeWhile(fn)
+	assert.Equal(t, []interface{}{}, s.AndThen().ToSlice())
+
+	s = Of(1, 2, 3, 1).TakeWhile(fn)
+	assert.Equal(t, []interface{}{1, 2}, s.AndThen().ToSlice())
+
+	s = Iterate(0, func(element interface{}) interface{} { return element.(int) + 1 }).TakeWhile(fn)
+	assert.Equal(t, []interface{}{1, 2}, s.AndThen().ToSlice())
+
+	// Finisher.TakeWhile
+	fin := Of().AndThen().TakeWhile(fn)
+	assert.Equal(t, []interface{}{}, fin.ToSlice())
+
+	fin = Of(1, 2, 3, 1).AndThen().TakeWhile(fn)
+	assert.Equal(t, []interface{}{1, 2}, fin.ToSlice())
+
+	fin = Iterate(0, func(element interface{}) interface{} { return element.(int) + 1 }).AndThen().TakeWhile(fn)
+	assert.Equal(t, []interface{}{1, 2}, fin.ToSlice())
 }
 
 func TestStreamMap(t *testing.T) {
@@ -145,6 +388,152 @@ func TestStreamMap(t *testing.T) {
 	assert.Equal(t, []string{"2", "4"}, s.AndThen().ToSliceOf(""))
 }
 
+func TestStreamMapIf(t *testing.T) {
+	var (
+		isOdd  = func(element interface{}) bool { return element.(int)%2 == 1 }
+		double = func(element interface{}) interface{} { return element.(int) * 2 }
+		negate = func(element interface{}) interface{} { return -element.(int) }
+	)
+
+	assert.Equal(t, []interface{}{}, Of().MapIf(isOdd, double, negate).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{2, -2, 6}, Of(1, 2, 3).MapIf(isOdd, double, negate).AndThen().ToSlice())
+
+	assert.Equal(t, []interface{}{}, Of().MapWhere(isOdd, double).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{2, 2, 6}, Of(1, 2, 3).MapWhere(isOdd, double).AndThen().ToSlice())
+}
+
+func TestStreamMapOrElse(t *testing.T) {
+	var (
+		errOdd = fmt.Errorf("odd")
+		half   = func(element interface{}) (interface{}, error) {
+			if element.(int)%2 == 1 {
+				return nil, errOdd
+			}
+			return element.(int) / 2, nil
+		}
+		errs     []error
+		fallback = func(element interface{}, err error) interface{} {
+			errs = append(errs, err)
+			return 0
+		}
+	)
+
+	assert.Equal(t, []interface{}{}, Of().MapOrElse(half, fallback).AndThen().ToSlice())
+	assert.Nil(t, errs)
+
+	assert.Equal(t, []interface{}{0, 1, 0, 2}, Of(1, 2, 3, 4).MapOrElse(half, fallback).AndThen().ToSlice())
+	assert.Equal(t, []error{errOdd, errOdd}, errs)
+}
+
+func TestStreamMapWithTimeout(t *testing.T) {
+	var (
+		block   = make(chan struct{})
+		mapping = func(element interface{}) interface{} {
+			if element.(int) == 2 {
+				<-block
+			}
+			return element.(int) * 2
+		}
+		fallback = func(element interface{}) (interface{}, error) { return -element.(int), nil }
+	)
+	defer close(block)
+
+	assert.Equal(t, []interface{}{}, Of().MapWithTimeout(mapping, time.Millisecond, fallback).AndThen().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{2, -2, 6},
+		Of(1, 2, 3).MapWithTimeout(mapping, 10*time.Millisecond, fallback).AndThen().ToSlice(),
+	)
+
+	// onTimeout error
+	err := fmt.Errorf("timed out")
+	func() {
+		defer func() {
+			assert.Equal(t, err, recover())
+		}()
+
+		Of(2).MapWithTimeout(mapping, time.Millisecond, func(interface{}) (interface{}, error) { return nil, err }).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// No onTimeout
+	func() {
+		defer func() {
+			assert.Equal(t, fmt.Sprintf(ErrMapTimeout, 2, time.Millisecond), recover())
+		}()
+
+		Of(2).MapWithTimeout(mapping, time.Millisecond, nil).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Panic in mapping function
+	func() {
+		defer func() {
+			assert.Equal(t, "bad", recover())
+		}()
+
+		Of(1).MapWithTimeout(func(interface{}) interface{} { panic("bad") }, time.Second, nil).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	func() {
+		defer func() {
+			assert.Equal(t, "d must be > 0", recover())
+		}()
+
+		Of().MapWithTimeout(mapping, 0, nil)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamMapBatched(t *testing.T) {
+	var (
+		batches [][]interface{}
+		double  = func(batch []interface{}) []interface{} {
+			batches = append(batches, batch)
+
+			result := make([]interface{}, len(batch))
+			for i, element := range batch {
+				result[i] = element.(int) * 2
+			}
+
+			return result
+		}
+	)
+
+	assert.Equal(t, []interface{}{}, Of().MapBatched(2, double).AndThen().ToSlice())
+	assert.Nil(t, batches)
+
+	assert.Equal(t, []interface{}{2, 4, 6, 8, 10}, Of(1, 2, 3, 4, 5).MapBatched(2, double).AndThen().ToSlice())
+	assert.Equal(t, [][]interface{}{{1, 2}, {3, 4}, {5}}, batches)
+
+	batches = nil
+	assert.Equal(t, []interface{}{2, 4}, Of(1, 2).MapBatched(2, double).AndThen().ToSlice())
+	assert.Equal(t, [][]interface{}{{1, 2}}, batches)
+
+	// Batches can produce any number of results
+	evens := func(batch []interface{}) []interface{} {
+		var result []interface{}
+		for _, element := range batch {
+			if element.(int)%2 == 0 {
+				result = append(result, element)
+			}
+		}
+
+		return result
+	}
+	assert.Equal(t, []interface{}{4, 6}, Of(1, 3, 4, 5, 7, 9, 6).MapBatched(2, evens).AndThen().ToSlice())
+
+	func() {
+		defer func() {
+			assert.Equal(t, "batchSize must be > 0", recover())
+		}()
+
+		Of().MapBatched(0, double)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
 func TestStreamPeek(t *testing.T) {
 	var elements []interface{}
 	fn := func(element interface{}) {
@@ -167,6 +556,216 @@ func TestStreamPeek(t *testing.T) {
 	assert.Equal(t, elements2, []int{1, 2}, s.AndThen().ToSliceOf(0))
 }
 
+func TestStreamSnapshot(t *testing.T) {
+	var (
+		reads  int
+		closed int
+		s      = Of(1, 2, 3).
+			Peek(func(interface{}) { reads++ }).
+			OnClose(func() error { closed++; return nil }).
+			Snapshot()
+	)
+
+	// Nothing is read until the first iteration
+	assert.Equal(t, 0, reads)
+
+	// Each Iter is a fresh iterator, and interleaved iterators are independent
+	it1, it2 := s.Iter(), s.Iter()
+	assert.True(t, it1.Next())
+	assert.Equal(t, 1, it1.Value())
+	assert.Equal(t, []interface{}{1, 2, 3}, it2.ToSlice())
+	assert.Equal(t, []interface{}{2, 3}, it1.ToSlice())
+
+	// Transforms after the snapshot are applied to each iteration
+	assert.Equal(t, []interface{}{2, 4, 6}, s.Map(func(element interface{}) interface{} { return element.(int) * 2 }).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, s.AndThen().ToSlice())
+	assert.Equal(t, 3, reads)
+	assert.Equal(t, 1, closed)
+
+	assert.Equal(t, []interface{}{}, Of().Snapshot().AndThen().ToSlice())
+
+	func() {
+		defer func() {
+			assert.Equal(t, ErrInfiniteFinisher, recover())
+		}()
+
+		Iterate(0, func(element interface{}) interface{} { return element }).Snapshot()
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamCache(t *testing.T) {
+	var mapped int
+	fn := func(element interface{}) interface{} {
+		mapped++
+		return element.(int) * 2
+	}
+
+	// Each terminal reads the same elements, which are only mapped once
+	s := Of(1, 2, 3).Map(fn).Cache()
+	assert.Equal(t, 0, mapped)
+	assert.Equal(t, []int{2, 4, 6}, s.AndThen().ToSliceOf(0))
+	assert.Equal(t, 12.0, s.AndThen().Sum().MustGet())
+	assert.Equal(t, 3, s.AndThen().Count())
+	assert.Equal(t, 3, mapped)
+
+	// A partial iteration only caches what it reads
+	mapped = 0
+	s = Of(1, 2, 3).Map(fn).Cache()
+	assert.Equal(t, 2, s.AndThen().FindFirst().MustGet())
+	assert.Equal(t, 1, mapped)
+	assert.Equal(t, []int{2, 4, 6}, s.AndThen().ToSliceOf(0))
+	assert.Equal(t, 3, mapped)
+
+	// Limit and TakeWhile restart on each iteration
+	var (
+		small   = func(element interface{}) bool { return element.(int) < 3 }
+		limited = Of(1, 2, 3).Cache().Limit(2)
+		taken   = Of(1, 2, 3).Cache().TakeWhile(small)
+		cached  = Of(1, 2, 3).Cache()
+		finLim  = cached.AndThen().Limit(2)
+		finTake = cached.AndThen().TakeWhile(small)
+	)
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, []int{1, 2}, limited.AndThen().ToSliceOf(0))
+		assert.Equal(t, []int{1, 2}, taken.AndThen().ToSliceOf(0))
+		assert.Equal(t, []int{1, 2}, finLim.ToSliceOf(0))
+		assert.Equal(t, []int{1, 2}, finTake.ToSliceOf(0))
+	}
+
+	// A partially read Stream that is not replayable continues where it left off
+	fin := Of(1, 2, 3).Limit(2).AndThen()
+	assert.Equal(t, 1, fin.FindFirst().MustGet())
+	assert.Equal(t, []int{2}, fin.ToSliceOf(0))
+
+	fin = Of(1, 2, 3).TakeWhile(small).AndThen()
+	assert.Equal(t, 1, fin.FindFirst().MustGet())
+	assert.Equal(t, []int{2}, fin.ToSliceOf(0))
+
+	// Repeated FindFirst on an infinite Stream stops at the limit
+	double := func(element interface{}) interface{} { return element.(int) * 2 }
+	for _, fin := range []Finisher{Iterate(1, double).AndThen().Limit(2), Iterate(1, double).Limit(2).AndThen()} {
+		assert.Equal(t, 2, fin.FindFirst().MustGet())
+		assert.Equal(t, 4, fin.FindFirst().MustGet())
+		assert.True(t, fin.FindFirst().IsEmpty())
+		assert.True(t, fin.FindFirst().IsEmpty())
+	}
+
+	// Interleaved iterations each have their own limit
+	for _, it := range [][2]*goiter.Iter{
+		{limited.Iter(), limited.Iter()},
+		{finLim.Iter(), finLim.Iter()},
+	} {
+		assert.True(t, it[0].Next())
+		assert.True(t, it[1].Next())
+		assert.Equal(t, []interface{}{2}, it[0].ToSlice())
+		assert.Equal(t, []interface{}{2}, it[1].ToSlice())
+	}
+
+	// Interleaved iterations
+	s = Of(1, 2).Cache()
+	it1, it2 := s.Iter(), s.Iter()
+	assert.True(t, it1.Next())
+	assert.Equal(t, 1, it1.Value())
+	assert.True(t, it2.Next())
+	assert.Equal(t, 1, it2.Value())
+	assert.True(t, it2.Next())
+	assert.Equal(t, 2, it2.Value())
+	assert.True(t, it1.Next())
+	assert.Equal(t, 2, it1.Value())
+	assert.False(t, it1.Next())
+	assert.False(t, it2.Next())
+
+	// Maximum not exceeded
+	s = Of(1, 2).Cache(2)
+	assert.Equal(t, []int{1, 2}, s.AndThen().ToSliceOf(0))
+	assert.Equal(t, []int{1, 2}, s.AndThen().ToSliceOf(0))
+
+	// Maximum exceeded
+	s = Of(1, 2, 3).Cache(2)
+	assert.Equal(t, []int{1, 2, 3}, s.AndThen().ToSliceOf(0))
+	func() {
+		defer func() {
+			assert.Equal(t, ErrCacheOverflow, recover())
+		}()
+
+		s.AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Infinite stream remains infinite
+	s = Iterate(0, func(element interface{}) interface{} { return element.(int) + 1 }).Cache()
+	assert.Equal(t, []int{1, 2, 3}, s.AndThen().Limit(3).ToSliceOf(0))
+	assert.Equal(t, []int{1, 2}, s.AndThen().Limit(2).ToSliceOf(0))
+}
+
+func TestStreamOnClose(t *testing.T) {
+	var closed []int
+	fn := func(i int, err error) func() error {
+		return func() error {
+			closed = append(closed, i)
+			return err
+		}
+	}
+
+	// No functions
+	assert.Nil(t, Of().Close())
+
+	// Closed by terminal, only once
+	s := Of(1, 2).OnClose(fn(1, nil)).Map(func(element interface{}) interface{} { return element }).OnClose(fn(2, nil))
+	assert.Equal(t, []interface{}{1, 2}, s.AndThen().ToSlice())
+	assert.Equal(t, []int{1, 2}, closed)
+	assert.Nil(t, s.Close())
+	assert.Equal(t, []int{1, 2}, closed)
+
+	// Errors are aggregated in order
+	closed = nil
+	err1, err2 := fmt.Errorf("err1"), fmt.Errorf("err2")
+	s = Of().OnClose(fn(1, err1)).OnClose(fn(2, nil)).OnClose(fn(3, err2))
+	assert.Equal(t, CloseErrors{err1, err2}, s.Close())
+	assert.Equal(t, "err1; err2", s.Close().Error())
+	assert.Equal(t, []int{1, 2, 3}, closed)
+
+	// Closed when a terminal returns early
+	closed = nil
+	s = Of(1, 2, 3).OnClose(fn(1, nil))
+	assert.True(t, s.AndThen().AnyMatch(func(element interface{}) bool { return element.(int) == 1 }))
+	assert.Equal(t, []int{1}, closed)
+
+	// Closed when a terminal panics
+	closed = nil
+	s = Of(1).OnClose(fn(1, nil)).Map(func(element interface{}) interface{} { panic("map") })
+	func() {
+		defer func() {
+			assert.Equal(t, "map", recover())
+		}()
+
+		s.AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+	assert.Equal(t, []int{1}, closed)
+
+	// Closed when a limited infinite stream terminates
+	closed = nil
+	s = Iterate(0, func(element interface{}) interface{} { return element }).OnClose(fn(1, nil))
+	assert.Equal(t, 2, s.AndThen().Limit(2).Count())
+	assert.Equal(t, []int{1}, closed)
+
+	// Closed when an iterator is exhausted
+	closed = nil
+	it := Of(1).OnClose(fn(1, nil)).Iter()
+	assert.True(t, it.Next())
+	assert.Nil(t, closed)
+	assert.False(t, it.Next())
+	assert.Equal(t, []int{1}, closed)
+
+	closed = nil
+	it = Of(1).OnClose(fn(1, nil)).AndThen().Limit(1).Iter()
+	assert.True(t, it.Next())
+	assert.False(t, it.Next())
+	assert.Equal(t, []int{1}, closed)
+}
+
 func TestStreamSkip(t *testing.T) {
 	s := Of().AndThen().Skip(0)
 	assert.Equal(t, []interface{}{}, s.ToSlice())
@@ -187,6 +786,507 @@ func TestStreamSkip(t *testing.T) {
 	assert.Equal(t, []interface{}{3, 4}, s.ToSlice())
 }
 
+func TestStreamTakeUntil(t *testing.T) {
+	done := make(chan struct{})
+	fin := Iterate(0, func(element interface{}) interface{} { return element.(int) + 1 }).
+		AndThen().
+		TakeUntil(done)
+
+	it := fin.Iter()
+	assert.True(t, it.Next())
+	assert.Equal(t, 1, it.Value())
+	assert.True(t, it.Next())
+	assert.Equal(t, 2, it.Value())
+
+	close(done)
+	assert.False(t, it.Next())
+
+	// Signal by sending a value
+	signal := make(chan struct{}, 1)
+	signal <- struct{}{}
+	assert.Equal(t, []interface{}{}, Of(1, 2).AndThen().TakeUntil(signal).ToSlice())
+
+	// No signal
+	assert.Equal(t, []interface{}{1, 2}, Of(1, 2).AndThen().TakeUntil(make(chan struct{})).ToSlice())
+
+	// A signal sent during one iteration of a replayable Stream does not stop later iterations
+	signal <- struct{}{}
+	fin = Of(1, 2).Cache().AndThen().TakeUntil(signal)
+	assert.Equal(t, []interface{}{}, fin.ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, fin.ToSlice())
+}
+
+func TestStreamRolling(t *testing.T) {
+	average := func(window []interface{}) interface{} {
+		sum := 0
+		for _, element := range window {
+			sum += element.(int)
+		}
+
+		return float64(sum) / float64(len(window))
+	}
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().Rolling(2, average).ToSlice())
+	assert.Equal(t, []interface{}{}, Of(1).AndThen().Rolling(2, average).ToSlice())
+	assert.Equal(t, []interface{}{1.0, 2.0}, Of(1, 2).AndThen().Rolling(1, average).ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{2.0, 3.0, 4.0, 5.0, 6.0},
+		Of(1, 2, 3, 4, 5, 6, 7).AndThen().Rolling(3, average).ToSlice(),
+	)
+
+	// Each iteration of a replayable Stream starts with an empty window
+	fin := Of(1, 2, 3, 4).Cache().AndThen().Rolling(2, average)
+	assert.Equal(t, []interface{}{1.5, 2.5, 3.5}, fin.ToSlice())
+	assert.Equal(t, []interface{}{1.5, 2.5, 3.5}, fin.ToSlice())
+
+	func() {
+		defer func() {
+			assert.Equal(t, "n must be > 0", recover())
+		}()
+
+		Of().AndThen().Rolling(0, average)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamRollingAggregate(t *testing.T) {
+	var (
+		add    = func(aggregate, element interface{}) interface{} { return aggregate.(int) + element.(int) }
+		remove = func(aggregate, element interface{}) interface{} { return aggregate.(int) - element.(int) }
+	)
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().RollingAggregate(2, 0, add, remove).ToSlice())
+	assert.Equal(t, []interface{}{}, Of(1).AndThen().RollingAggregate(2, 0, add, remove).ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, Of(1, 2).AndThen().RollingAggregate(1, 0, add, remove).ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{6, 9, 12, 15, 18},
+		Of(1, 2, 3, 4, 5, 6, 7).AndThen().RollingAggregate(3, 0, add, remove).ToSlice(),
+	)
+
+	// Each iteration of a replayable Stream starts with an empty window
+	fin := Of(1, 2, 3, 4).Cache().AndThen().RollingAggregate(2, 0, add, remove)
+	assert.Equal(t, []interface{}{3, 5, 7}, fin.ToSlice())
+	assert.Equal(t, []interface{}{3, 5, 7}, fin.ToSlice())
+
+	func() {
+		defer func() {
+			assert.Equal(t, "n must be > 0", recover())
+		}()
+
+		Of().AndThen().RollingAggregate(0, 0, add, remove)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamWindowByTime(t *testing.T) {
+	var (
+		base    = time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+		at      = func(seconds int) time.Time { return base.Add(time.Duration(seconds) * time.Second) }
+		extract = func(element interface{}) time.Time { return element.(time.Time) }
+	)
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().WindowByTime(extract, time.Minute).ToSlice())
+
+	assert.Equal(
+		t,
+		[]interface{}{
+			[]interface{}{at(0), at(59)},
+			[]interface{}{at(60)},
+			[]interface{}{at(185), at(239)},
+			[]interface{}{at(30)},
+		},
+		Of(at(0), at(59), at(60), at(185), at(239), at(30)).AndThen().WindowByTime(extract, time.Minute).ToSlice(),
+	)
+
+	// Each iteration of a replayable Stream starts over
+	fin := Of(at(0), at(60)).Cache().AndThen().WindowByTime(extract, time.Minute)
+	assert.Equal(t, []interface{}{[]interface{}{at(0)}, []interface{}{at(60)}}, fin.ToSlice())
+	assert.Equal(t, []interface{}{[]interface{}{at(0)}, []interface{}{at(60)}}, fin.ToSlice())
+
+	func() {
+		defer func() {
+			assert.Equal(t, "window must be > 0", recover())
+		}()
+
+		Of().AndThen().WindowByTime(extract, 0)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamBuffer(t *testing.T) {
+	// Block
+	assert.Equal(t, []interface{}{}, Of().AndThen().Buffer(1, BlockOnOverflow).ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, Of(1, 2, 3).AndThen().Buffer(1, BlockOnOverflow).ToSlice())
+
+	// Drop newest: the consumer waits until the source is exhausted before reading
+	exhausted := make(chan struct{})
+	it := Of(1, 2, 3, 4).
+		AndThen().
+		Transform(func(it *goiter.Iter) *goiter.Iter {
+			return goiter.NewIter(func() (interface{}, bool) {
+				if it.Next() {
+					return it.Value(), true
+				}
+
+				close(exhausted)
+				return nil, false
+			})
+		}).
+		Buffer(2, DropNewest).
+		Iter()
+	started := it.Next()
+	<-exhausted
+	assert.True(t, started)
+
+	rest := []interface{}{it.Value()}
+	for it.Next() {
+		rest = append(rest, it.Value())
+	}
+	assert.True(t, len(rest) <= 3)
+	assert.Equal(t, 1, rest[0])
+
+	// Drop oldest keeps the last element
+	exhausted = make(chan struct{})
+	it = Of(1, 2, 3, 4, 5).
+		AndThen().
+		Transform(func(it *goiter.Iter) *goiter.Iter {
+			return goiter.NewIter(func() (interface{}, bool) {
+				if it.Next() {
+					return it.Value(), true
+				}
+
+				close(exhausted)
+				return nil, false
+			})
+		}).
+		Buffer(2, DropOldest).
+		Iter()
+	assert.True(t, it.Next())
+	<-exhausted
+
+	var last interface{}
+	for it.Next() {
+		last = it.Value()
+	}
+	assert.Equal(t, 5, last)
+
+	// Source panic is rethrown to the consumer
+	func() {
+		defer func() {
+			assert.Equal(t, "boom", recover())
+		}()
+
+		Of(1, 2).Map(func(element interface{}) interface{} {
+			if element == 2 {
+				panic("boom")
+			}
+			return element
+		}).AndThen().Buffer(1, BlockOnOverflow).ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Closing early stops the goroutine
+	fin := Iterate(0, func(element interface{}) interface{} { return element.(int) + 1 }).AndThen().Buffer(1, BlockOnOverflow)
+	it = fin.Limit(2).Iter()
+	assert.True(t, it.Next())
+	assert.Nil(t, fin.Close())
+
+	// Each iteration of a replayable Stream has its own buffer
+	fin = Of(1, 2, 3).Cache().AndThen().Buffer(1, BlockOnOverflow)
+	assert.Equal(t, []interface{}{1, 2, 3}, fin.ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, fin.ToSlice())
+
+	func() {
+		defer func() {
+			assert.Equal(t, "n must be > 0", recover())
+		}()
+
+		Of().AndThen().Buffer(0, DropNewest)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamReplace(t *testing.T) {
+	isOdd := func(element interface{}) bool { return element.(int)%2 == 1 }
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().Replace(isOdd, 0, 1).ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, Of(1, 2, 3).AndThen().Replace(isOdd, 0, 0).ToSlice())
+	assert.Equal(t, []interface{}{0, 2, 3, 5}, Of(1, 2, 3, 5).AndThen().Replace(isOdd, 0, 1).ToSlice())
+	assert.Equal(t, []interface{}{0, 2, 0, 5}, Of(1, 2, 3, 5).AndThen().Replace(isOdd, 0, 2).ToSlice())
+	assert.Equal(t, []interface{}{0, 2, 0, 0}, Of(1, 2, 3, 5).AndThen().Replace(isOdd, 0, -1).ToSlice())
+	assert.Equal(t, []interface{}{0, 2, 0, 0}, Of(1, 2, 3, 5).AndThen().ReplaceAll(isOdd, 0).ToSlice())
+
+	// Each iteration of a replayable Stream starts over
+	fin := Of(1, 2, 3, 4).Cache().AndThen().Replace(isOdd, 0, 1)
+	assert.Equal(t, []interface{}{0, 2, 3, 4}, fin.ToSlice())
+	assert.Equal(t, []interface{}{0, 2, 3, 4}, fin.ToSlice())
+}
+
+func TestStreamPadTo(t *testing.T) {
+	assert.Equal(t, []interface{}{}, Of().AndThen().PadTo(0, 0).ToSlice())
+	assert.Equal(t, []interface{}{0, 0}, Of().AndThen().PadTo(2, 0).ToSlice())
+	assert.Equal(t, []interface{}{1, 0, 0}, Of(1).AndThen().PadTo(3, 0).ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, Of(1, 2, 3).AndThen().PadTo(2, 0).ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, Of(1, 2).AndThen().PadTo(-1, 0).ToSlice())
+
+	// Each iteration of a replayable Stream starts over
+	fin := Of(1).Cache().AndThen().PadTo(3, 0)
+	assert.Equal(t, []interface{}{1, 0, 0}, fin.ToSlice())
+	assert.Equal(t, []interface{}{1, 0, 0}, fin.ToSlice())
+}
+
+func TestStreamRotateLeft(t *testing.T) {
+	assert.Equal(t, []interface{}{}, Of().AndThen().RotateLeft(2).ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, Of(1, 2, 3).AndThen().RotateLeft(0).ToSlice())
+	assert.Equal(t, []interface{}{2, 3, 1}, Of(1, 2, 3).AndThen().RotateLeft(1).ToSlice())
+	assert.Equal(t, []interface{}{3, 4, 5, 1, 2}, Of(1, 2, 3, 4, 5).AndThen().RotateLeft(2).ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, Of(1, 2, 3).AndThen().RotateLeft(3).ToSlice())
+	assert.Equal(t, []interface{}{2, 3, 1}, Of(1, 2, 3).AndThen().RotateLeft(4).ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, Of(1, 2, 3).AndThen().RotateLeft(6).ToSlice())
+
+	// Each iteration of a replayable Stream starts over
+	for _, fin := range []Finisher{Of(1, 2, 3).Cache().AndThen().RotateLeft(1), Of(1, 2, 3).Snapshot().AndThen().RotateLeft(4)} {
+		assert.Equal(t, []interface{}{2, 3, 1}, fin.ToSlice())
+		assert.Equal(t, []interface{}{2, 3, 1}, fin.ToSlice())
+	}
+}
+
+func TestStreamRotateRight(t *testing.T) {
+	assert.Equal(t, []interface{}{}, Of().AndThen().RotateRight(2).ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, Of(1, 2, 3).AndThen().RotateRight(0).ToSlice())
+	assert.Equal(t, []interface{}{3, 1, 2}, Of(1, 2, 3).AndThen().RotateRight(1).ToSlice())
+	assert.Equal(t, []interface{}{4, 5, 1, 2, 3}, Of(1, 2, 3, 4, 5).AndThen().RotateRight(2).ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, Of(1, 2, 3).AndThen().RotateRight(3).ToSlice())
+	assert.Equal(t, []interface{}{3, 1, 2}, Of(1, 2, 3).AndThen().RotateRight(4).ToSlice())
+
+	// Each iteration of a replayable Stream starts over
+	for _, fin := range []Finisher{Of(1, 2, 3).Cache().AndThen().RotateRight(1), Of(1, 2, 3).Snapshot().AndThen().RotateRight(1)} {
+		assert.Equal(t, []interface{}{3, 1, 2}, fin.ToSlice())
+		assert.Equal(t, []interface{}{3, 1, 2}, fin.ToSlice())
+	}
+}
+
+func TestStreamSkipLast(t *testing.T) {
+	assert.Equal(t, []interface{}{}, Of().AndThen().SkipLast(2).ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, Of(1, 2).AndThen().SkipLast(0).ToSlice())
+	assert.Equal(t, []interface{}{}, Of(1, 2).AndThen().SkipLast(2).ToSlice())
+	assert.Equal(t, []interface{}{}, Of(1, 2).AndThen().SkipLast(3).ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, Of(1, 2, 3, 4, 5).AndThen().SkipLast(2).ToSlice())
+
+	// Elements are returned before the Stream is exhausted
+	var read []interface{}
+	it := Of(1, 2, 3, 4).Peek(func(element interface{}) { read = append(read, element) }).AndThen().SkipLast(2).Iter()
+	assert.True(t, it.Next())
+	assert.Equal(t, 1, it.Value())
+	assert.Equal(t, []interface{}{1, 2, 3}, read)
+
+	// Each iteration of a replayable Stream starts over
+	fin := Of(1, 2, 3, 4).Cache().AndThen().SkipLast(1)
+	assert.Equal(t, []interface{}{1, 2, 3}, fin.ToSlice())
+	assert.Equal(t, []interface{}{1, 2, 3}, fin.ToSlice())
+}
+
+func TestStreamTakeLast(t *testing.T) {
+	assert.Equal(t, []interface{}{}, Of().AndThen().TakeLast(2).ToSlice())
+	assert.Equal(t, []interface{}{}, Of(1, 2).AndThen().TakeLast(0).ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, Of(1, 2).AndThen().TakeLast(2).ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, Of(1, 2).AndThen().TakeLast(3).ToSlice())
+	assert.Equal(t, []interface{}{4, 5}, Of(1, 2, 3, 4, 5).AndThen().TakeLast(2).ToSlice())
+	assert.Equal(t, []interface{}{3, 4, 5}, Of(1, 2, 3, 4, 5).AndThen().TakeLast(3).ToSlice())
+
+	// Each iteration of a replayable Stream starts over
+	fin := Of(1, 2, 3, 4).Cache().AndThen().TakeLast(2)
+	assert.Equal(t, []interface{}{3, 4}, fin.ToSlice())
+	assert.Equal(t, []interface{}{3, 4}, fin.ToSlice())
+}
+
+func TestStreamSortedNatural(t *testing.T) {
+	type myString string
+
+	var (
+		now  = time.Now()
+		then = now.Add(-time.Hour)
+	)
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().SortedNatural().ToSlice())
+	assert.Equal(t, []interface{}{int8(-1), 2, int64(3)}, Of(int64(3), int8(-1), 2).AndThen().SortedNatural().ToSlice())
+	assert.Equal(t, []interface{}{uint8(1), uint(2)}, Of(uint(2), uint8(1)).AndThen().SortedNatural().ToSlice())
+	assert.Equal(t, []interface{}{float32(1.5), 2.5}, Of(2.5, float32(1.5)).AndThen().SortedNatural().ToSlice())
+	assert.Equal(t, []interface{}{"a", myString("b")}, Of(myString("b"), "a").AndThen().SortedNatural().ToSlice())
+	assert.Equal(t, []interface{}{then, now}, Of(now, then).AndThen().SortedNatural().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{big.NewInt(1), big.NewInt(2)},
+		Of(big.NewInt(2), big.NewInt(1)).AndThen().SortedNatural().ToSlice(),
+	)
+	assert.Equal(
+		t,
+		[]interface{}{big.NewRat(1, 3), big.NewRat(1, 2)},
+		Of(big.NewRat(1, 2), big.NewRat(1, 3)).AndThen().SortedNatural().ToSlice(),
+	)
+	assert.Equal(
+		t,
+		[]interface{}{big.NewFloat(1.5), big.NewFloat(2.5)},
+		Of(big.NewFloat(2.5), big.NewFloat(1.5)).AndThen().SortedNatural().ToSlice(),
+	)
+
+	for _, elements := range [][]interface{}{{1, uint(2)}, {"a", 1}, {now, 1}, {big.NewInt(1), 1}, {[]int{1}, []int{2}}, {nil, 1}} {
+		func() {
+			defer func() {
+				assert.Equal(t, fmt.Sprintf(ErrNaturalOrder, elements[1], elements[0]), recover())
+			}()
+
+			Of(elements...).AndThen().SortedNatural().ToSlice()
+			assert.Fail(t, "Must panic")
+		}()
+	}
+}
+
+func TestStreamPeekEvery(t *testing.T) {
+	var peeked []interface{}
+	peek := func(element interface{}) { peeked = append(peeked, element) }
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().PeekEvery(2, peek).ToSlice())
+	assert.Nil(t, peeked)
+
+	assert.Equal(t, []interface{}{1, 2, 3, 4, 5, 6, 7}, Of(1, 2, 3, 4, 5, 6, 7).AndThen().PeekEvery(3, peek).ToSlice())
+	assert.Equal(t, []interface{}{1, 4, 7}, peeked)
+
+	// Each iteration of a replayable Stream starts at the first element
+	peeked = nil
+	fin := Of(1, 2, 3).Cache().AndThen().PeekEvery(2, peek)
+	fin.ToSlice()
+	fin.ToSlice()
+	assert.Equal(t, []interface{}{1, 3, 1, 3}, peeked)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "n must be > 0", recover())
+		}()
+
+		Of().AndThen().PeekEvery(0, peek)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamPeekSample(t *testing.T) {
+	var (
+		peeked int
+		peek   = func(interface{}) { peeked++ }
+		inc    = func(element interface{}) interface{} { return element.(int) + 1 }
+	)
+
+	assert.Equal(t, 1000, Iterate(0, inc).AndThen().PeekSample(0.1, rand.New(rand.NewSource(1)), peek).Limit(1000).Count())
+	assert.True(t, (peeked > 50) && (peeked < 150), "%d peeked", peeked)
+
+	peeked = 0
+	Of(1, 2, 3).AndThen().PeekSample(0, nil, peek).Count()
+	assert.Equal(t, 0, peeked)
+
+	Of(1, 2, 3).AndThen().PeekSample(1, nil, peek).Count()
+	assert.Equal(t, 3, peeked)
+
+	for _, rate := range []float64{-0.1, 1.1} {
+		func() {
+			defer func() {
+				assert.Equal(t, "rate must be >= 0 and <= 1", recover())
+			}()
+
+			Of().AndThen().PeekSample(rate, nil, peek)
+			assert.Fail(t, "Must panic")
+		}()
+	}
+}
+
+func TestStreamWithProgress(t *testing.T) {
+	var (
+		reports []int64
+		report  = func(processed int64, elapsed time.Duration) {
+			assert.True(t, elapsed >= 0)
+			reports = append(reports, processed)
+		}
+		odd = func(element interface{}) bool { return element.(int)%2 == 1 }
+	)
+
+	assert.Equal(t, 7, Of(1, 2, 3, 4, 5, 6, 7).AndThen().WithProgress(3, report).Count())
+	assert.Equal(t, []int64{3, 6}, reports)
+
+	// Only elements that reach the progress point are counted
+	reports = nil
+	assert.Equal(t, 4, Of(1, 2, 3, 4, 5, 6, 7).AndThen().Filter(odd).WithProgress(2, report).Count())
+	assert.Equal(t, []int64{2, 4}, reports)
+
+	reports = nil
+	assert.Equal(t, 0, Of().AndThen().WithProgress(1, report).Count())
+	assert.Nil(t, reports)
+
+	// Each iteration of a replayable Stream counts from the start
+	fin := Of(1, 2, 3).Cache().AndThen().WithProgress(2, report)
+	assert.Equal(t, 3, fin.Count())
+	assert.Equal(t, 3, fin.Count())
+	assert.Equal(t, []int64{2, 2}, reports)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "every must be > 0", recover())
+		}()
+
+		Of().AndThen().WithProgress(0, report)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamPeekIndexed(t *testing.T) {
+	var (
+		indexes  []int
+		elements []interface{}
+		fn       = func(index int, element interface{}) {
+			indexes = append(indexes, index)
+			elements = append(elements, element)
+		}
+	)
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().PeekIndexed("empty", fn).ToSlice())
+	assert.Nil(t, indexes)
+
+	assert.Equal(t, []interface{}{"a", "b"}, Of("a", "b").AndThen().PeekIndexed("two", fn).ToSlice())
+	assert.Equal(t, []int{0, 1}, indexes)
+	assert.Equal(t, []interface{}{"a", "b"}, elements)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "odd: panic at element 1: boom", recover())
+		}()
+
+		Of(2, 3).AndThen().PeekIndexed("odd", func(index int, element interface{}) {
+			if element.(int)%2 == 1 {
+				panic("boom")
+			}
+		}).ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// An error is rethrown as is
+	boom := errors.New("boom")
+	func() {
+		defer func() {
+			assert.Equal(t, boom, recover())
+		}()
+
+		Of(1).AndThen().PeekIndexed("error", func(int, interface{}) { panic(boom) }).ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+
+	// Each iteration of a replayable Stream starts at index 0
+	indexes = nil
+	fin := Of("a", "b").Cache().AndThen().PeekIndexed("cached", fn)
+	fin.ToSlice()
+	fin.ToSlice()
+	assert.Equal(t, []int{0, 1, 0, 1}, indexes)
+}
+
 func TestStreamSorted(t *testing.T) {
 	fn := func(element1, element2 interface{}) bool {
 		return element1.(int) < element2.(int)
@@ -300,6 +1400,70 @@ func TestStreamAnyMatch(t *testing.T) {
 	assert.True(t, s.AndThen().AnyMatch(fn))
 }
 
+func TestStreamMatchIndexed(t *testing.T) {
+	// Elements must equal their index
+	var (
+		reads   int
+		atIndex = func(index int, element interface{}) bool { reads++; return index == element.(int) }
+	)
+
+	assert.True(t, Of().AndThen().AllMatchIndexed(atIndex))
+	assert.True(t, Of(0, 1, 2).AndThen().AllMatchIndexed(atIndex))
+	reads = 0
+	assert.False(t, Of(0, 2, 2).AndThen().AllMatchIndexed(atIndex))
+	assert.Equal(t, 2, reads)
+
+	assert.False(t, Of().AndThen().AnyMatchIndexed(atIndex))
+	reads = 0
+	assert.True(t, Of(1, 1, 1).AndThen().AnyMatchIndexed(atIndex))
+	assert.Equal(t, 2, reads)
+	assert.False(t, Of(1, 0).AndThen().AnyMatchIndexed(atIndex))
+
+	assert.True(t, Of().AndThen().NoneMatchIndexed(atIndex))
+	assert.True(t, Of(1, 0).AndThen().NoneMatchIndexed(atIndex))
+	assert.False(t, Of(1, 1).AndThen().NoneMatchIndexed(atIndex))
+}
+
+func TestStreamAllDistinct(t *testing.T) {
+	assert.True(t, Of().AndThen().AllDistinct())
+	assert.True(t, Of(1, 2, 3).AndThen().AllDistinct())
+	assert.False(t, Of(1, 2, 1).AndThen().AllDistinct())
+
+	// Short circuit
+	read := 0
+	assert.False(t, Of(1, 1, 2, 3).Peek(func(interface{}) { read++ }).AndThen().AllDistinct())
+	assert.Equal(t, 2, read)
+
+	// Key
+	mod2 := func(element interface{}) interface{} { return element.(int) % 2 }
+	assert.True(t, Of(1, 2).AndThen().AllDistinct(mod2))
+	assert.False(t, Of(1, 2, 3).AndThen().AllDistinct(mod2))
+
+	// Elements and keys that cannot be map keys
+	assert.True(t, Of([]int{1}, []int{2}).AndThen().AllDistinct())
+	assert.False(t, Of([]int{1}, 2, []int{1}).AndThen().AllDistinct())
+	mod2Slice := func(element interface{}) interface{} { return []int{element.(int) % 2} }
+	assert.True(t, Of(1, 2).AndThen().AllDistinct(mod2Slice))
+	assert.False(t, Of(1, 2, 3).AndThen().AllDistinct(mod2Slice))
+}
+
+func TestStreamIndexOf(t *testing.T) {
+	isOdd := func(element interface{}) bool { return element.(int)%2 == 1 }
+
+	assert.Equal(t, -1, Of().AndThen().IndexOf(isOdd))
+	assert.Equal(t, -1, Of(2, 4).AndThen().IndexOf(isOdd))
+	assert.Equal(t, 1, Of(2, 3, 4, 5).AndThen().IndexOf(isOdd))
+
+	// IndexOf stops at the first match
+	var read []int
+	assert.Equal(t, 1, Of(2, 3, 4, 5).Peek(func(element interface{}) { read = append(read, element.(int)) }).AndThen().IndexOf(isOdd))
+	assert.Equal(t, []int{2, 3}, read)
+
+	assert.Equal(t, -1, Of().AndThen().LastIndexOf(isOdd))
+	assert.Equal(t, -1, Of(2, 4).AndThen().LastIndexOf(isOdd))
+	assert.Equal(t, 3, Of(2, 3, 4, 5, 6).AndThen().LastIndexOf(isOdd))
+}
+
 func TestStreamAverage(t *testing.T) {
 	s := Of(1, 2.25)
 	avg := (1 + 2.25) / 2
@@ -372,6 +1536,113 @@ func TestStreamGroupBy(t *testing.T) {
 	assert.Equal(t, map[interface{}][]interface{}{0: {0}, 1: {1, 4}}, s.AndThen().GroupBy(fn))
 }
 
+func TestStreamGroupByOf(t *testing.T) {
+	type order struct {
+		customer string
+		amount   int
+	}
+
+	var (
+		customer = func(element interface{}) (key interface{}) { return element.(order).customer }
+		mod3     = func(element interface{}) (key interface{}) { return element.(int) % 3 }
+	)
+
+	assert.Equal(t, map[string][]order{}, Of().AndThen().GroupByOf(customer, "", order{}))
+	assert.Equal(
+		t,
+		map[string][]order{"a": {{"a", 1}, {"a", 3}}, "b": {{"b", 2}}},
+		Of(order{"a", 1}, order{"b", 2}, order{"a", 3}).AndThen().GroupByOf(customer, "", order{}),
+	)
+
+	// Keys and elements are converted
+	assert.Equal(
+		t,
+		map[int8][]int64{0: {0, 3}, 1: {1, 4}},
+		Of(0, 1, 3, 4).AndThen().GroupByOf(mod3, int8(0), int64(0)),
+	)
+
+	func() {
+		defer func() {
+			assert.NotNil(t, recover())
+		}()
+
+		Of(1.5).AndThen().GroupByOf(func(interface{}) interface{} { return 0 }, 0, true)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamReduceByKey(t *testing.T) {
+	var (
+		key = func(element interface{}) interface{} { return element.(int) % 3 }
+		fn  = func(accumulator, element interface{}) interface{} { return accumulator.(int) + element.(int) }
+	)
+	s := Of()
+	assert.Equal(t, map[interface{}]interface{}{}, s.AndThen().ReduceByKey(key, 0, fn))
+
+	s = Of(0, 1, 3, 4, 7)
+	assert.Equal(t, map[interface{}]interface{}{0: 3, 1: 12}, s.AndThen().ReduceByKey(key, 0, fn))
+
+	s = Of(0, 1, 3, 4, 7)
+	assert.Equal(t, map[interface{}]interface{}{0: 103, 1: 112}, s.AndThen().ReduceByKey(key, 100, fn))
+}
+
+func TestStreamAggregateByKey(t *testing.T) {
+	var (
+		key      = func(element interface{}) interface{} { return element.(int) % 3 }
+		identity = func() interface{} { return map[int]bool{} }
+		fn       = func(accumulator, element interface{}) interface{} {
+			accumulator.(map[int]bool)[element.(int)] = true
+			return accumulator
+		}
+	)
+	s := Of()
+	assert.Equal(t, map[interface{}]interface{}{}, s.AndThen().AggregateByKey(key, identity, fn))
+
+	s = Of(0, 1, 3, 4, 3)
+	assert.Equal(
+		t,
+		map[interface{}]interface{}{0: map[int]bool{0: true, 3: true}, 1: map[int]bool{1: true, 4: true}},
+		s.AndThen().AggregateByKey(key, identity, fn),
+	)
+}
+
+func TestStreamGroupByStream(t *testing.T) {
+	fn := func(element interface{}) (key interface{}) {
+		return element.(int) % 3
+	}
+	s := Of().AndThen().GroupByStream(fn)
+	assert.Equal(t, []interface{}{}, s.AndThen().ToSlice())
+
+	s = Of(4, 0, 1, 3, 7).AndThen().GroupByStream(fn)
+	groups := s.AndThen().ToSlice()
+	assert.Equal(t, 2, len(groups))
+	assert.Equal(t, 1, groups[0].(Group).Key)
+	assert.Equal(t, []int{4, 1, 7}, groups[0].(Group).Elements.AndThen().ToSliceOf(0))
+	assert.Equal(t, 0, groups[1].(Group).Key)
+	assert.Equal(t, []int{0, 3}, groups[1].(Group).Elements.AndThen().ToSliceOf(0))
+
+	// Groups can be further processed
+	s = Of(4, 0, 1, 3, 7, 5).AndThen().GroupByStream(fn)
+	assert.Equal(
+		t,
+		[]int{0, 1, 2},
+		s.Map(func(element interface{}) interface{} { return element.(Group).Key }).
+			AndThen().
+			Sorted(gofuncs.IntSortFunc).
+			ToSliceOf(0),
+	)
+
+	// Panic on infinite Finisher
+	func() {
+		defer func() {
+			assert.Equal(t, ErrInfiniteFinisher, recover())
+		}()
+
+		Iterate(1, func(element interface{}) interface{} { return element }).AndThen().GroupByStream(fn)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
 func TestStreamLast(t *testing.T) {
 	s := Of()
 	last := s.AndThen().Last()
@@ -474,6 +1745,114 @@ func TestStreamToSliceOf(t *testing.T) {
 	assert.Equal(t, []int{1, 2}, s.AndThen().ToSliceOf(0))
 }
 
+func TestStreamFillSlice(t *testing.T) {
+	// Slice larger than the stream
+	dest := make([]int, 3)
+	n, err := Of(1, int8(2)).AndThen().FillSlice(dest)
+	assert.Equal(t, 2, n)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 0}, dest)
+
+	// Slice smaller than the stream only reads len(dest) elements
+	read := 0
+	n, err = Of(1, 2, 3).Peek(func(interface{}) { read++ }).AndThen().FillSlice(dest[:2])
+	assert.Equal(t, 2, n)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, read)
+
+	// Pointer to array
+	var array [2]string
+	n, err = Of("a", "b", "c").AndThen().FillSlice(&array)
+	assert.Equal(t, 2, n)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]string{"a", "b"}, array)
+
+	// Nil elements
+	ptrs := make([]*int, 1)
+	n, err = Of(nil).AndThen().FillSlice(ptrs)
+	assert.Equal(t, 1, n)
+	assert.Nil(t, err)
+	assert.Equal(t, []*int{nil}, ptrs)
+
+	n, err = Of(5, nil).AndThen().FillSlice(dest)
+	assert.Equal(t, 1, n)
+	assert.EqualError(t, err, "element 1: nil is not convertible to int")
+
+	// Not convertible
+	n, err = Of(5, "a").AndThen().FillSlice(dest)
+	assert.Equal(t, 1, n)
+	assert.EqualError(t, err, "element 1: type string is not convertible to int")
+
+	func() {
+		defer func() {
+			assert.Equal(t, "dest must be a slice or pointer to an array", recover())
+		}()
+
+		Of().AndThen().FillSlice(array)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamToStream(t *testing.T) {
+	var (
+		reads  int
+		closed int
+		inc    = func(element interface{}) interface{} { return element.(int) + 1 }
+		fin    = Of(1, 2, 3).
+			Peek(func(interface{}) { reads++ }).
+			OnClose(func() error { closed++; return nil }).
+			AndThen().
+			Skip(1)
+		s = fin.ToStream()
+	)
+
+	// Nothing is read until the result is iterated
+	assert.Equal(t, 0, reads)
+	assert.Equal(t, []interface{}{3, 4}, s.Map(inc).AndThen().ToSlice())
+	assert.Equal(t, 3, reads)
+	assert.Equal(t, 1, closed)
+
+	// Infinite
+	assert.Equal(t, []interface{}{2, 3}, Iterate(0, inc).AndThen().ToStream().Map(inc).Limit(2).AndThen().ToSlice())
+
+	// Closing the result early closes the Finisher
+	closed = 0
+	it := Of(1, 2).OnClose(func() error { closed++; return nil }).AndThen().ToStream().Iter()
+	assert.True(t, it.Next())
+	assert.Equal(t, 0, closed)
+	assert.Equal(t, []interface{}{}, Of(1, 2).OnClose(func() error { closed++; return nil }).AndThen().ToStream().Limit(0).AndThen().ToSlice())
+	assert.Equal(t, 1, closed)
+}
+
+func TestStreamToMaterializedStream(t *testing.T) {
+	var (
+		reads int
+		s     = Of(1, 2).Peek(func(interface{}) { reads++ }).AndThen().ToMaterializedStream()
+	)
+
+	assert.Equal(t, 2, reads)
+	assert.Equal(t, []interface{}{1, 2}, s.AndThen().ToSlice())
+
+	func() {
+		defer func() {
+			assert.Equal(t, ErrInfiniteFinisher, recover())
+		}()
+
+		Iterate(0, func(element interface{}) interface{} { return element }).AndThen().ToMaterializedStream()
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamToSortedSliceOf(t *testing.T) {
+	less := func(element1, element2 interface{}) bool { return element1.(int) < element2.(int) }
+
+	assert.Equal(t, []int{}, Of().AndThen().ToSortedSliceOf(0, less))
+	assert.Equal(t, []int{1, 2, 3}, Of(3, 1, 2).AndThen().ToSortedSliceOf(0, less))
+
+	// Elements are converted before sorting
+	assert.Equal(t, []int{1, 2, 3}, Of(int8(2), int16(3), 1).AndThen().ToSortedSliceOf(0, less))
+}
+
 // ==== Sequence
 
 func TestSequence(t *testing.T) {
@@ -543,3 +1922,93 @@ func TestParallel(t *testing.T) {
 	s = OfIterables(goiter.OfElements(input)).Map(doubler).AndThen().Distinct()
 	assert.Equal(t, doubledDistinct, s.ParallelToSliceOf(0, 0))
 }
+
+func TestParallelMixedPhases(t *testing.T) {
+	var (
+		input    = []int{1, 2, 1, 3, 4, 3, 5, 6, 7, 7, 8, 9, 10}
+		mapCalls int32
+		doubler  = func(element interface{}) interface{} {
+			atomic.AddInt32(&mapCalls, 1)
+			return element.(int) * 2
+		}
+		filterCalls int
+		even4       = func(element interface{}) bool {
+			filterCalls++
+			return element.(int)%4 == 0
+		}
+		flags = []ParallelFlags{NumberOfGoroutines, NumberOfItemsPerGoroutine, WorkStealing}
+	)
+
+	for _, flag := range flags {
+		for _, numItems := range []uint{0, 1, 3, 20} {
+			// Stream Map runs once per element, Finisher Filter runs once per mapped element
+			mapCalls, filterCalls = 0, 0
+			assert.Equal(
+				t,
+				[]int{4, 8, 12, 16, 20},
+				Of(1, 2, 3, 4, 5, 6, 7, 8, 9, 10).Map(doubler).AndThen().Filter(even4).ParallelToSliceOf(0, numItems, flag),
+			)
+			assert.Equal(t, int32(10), mapCalls)
+			assert.Equal(t, 10, filterCalls)
+
+			// Sequential Limit followed by Map, then a Finisher Sorted
+			mapCalls = 0
+			assert.Equal(
+				t,
+				[]int{2, 2, 4, 6, 6, 8},
+				OfIterables(goiter.OfElements(input)).Limit(6).Map(doubler).AndThen().Sorted(gofuncs.IntSortFunc).ParallelToSliceOf(0, numItems, flag),
+			)
+			assert.Equal(t, int32(6), mapCalls)
+
+			// All parallel terminals produce the same result
+			var (
+				fin = func() Finisher {
+					return OfIterables(goiter.OfElements(input)).Map(doubler).AndThen().Distinct().Filter(even4)
+				}
+				expected = []interface{}{4, 8, 12, 16, 20}
+			)
+			assert.Equal(t, expected, fin().ParallelToSlice(numItems, flag))
+			assert.Equal(t, []int{4, 8, 12, 16, 20}, fin().ParallelToSliceOf(0, numItems, flag))
+			assert.Equal(t, expected, fin().ParallelToStream(numItems, flag).AndThen().ToSlice())
+		}
+	}
+}
+
+func TestParallelAdaptive(t *testing.T) {
+	var (
+		doubler  = gofuncs.Map(func(i int) int { return i * 2 })
+		slow     = func(element interface{}) interface{} { time.Sleep(100 * time.Microsecond); return element.(int) * 2 }
+		input    []interface{}
+		expected []interface{}
+	)
+
+	for i := 0; i < 100; i++ {
+		input = append(input, i)
+		expected = append(expected, i*2)
+	}
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().Parallel().AndThen().ToSlice())
+	assert.Equal(t, []interface{}{1, 2}, Of(2, 1, 2).AndThen().Distinct().Sorted(gofuncs.IntSortFunc).Parallel().AndThen().ToSlice())
+	assert.Equal(t, []interface{}{2, 4}, Of(1, 2).Map(doubler).AndThen().Parallel().AndThen().ToSlice())
+
+	// Cheap elements
+	assert.Equal(t, expected, Of(input...).Map(doubler).AndThen().Parallel().AndThen().ToSlice())
+
+	// Work stealing
+	assert.Equal(t, expected, Of(input...).Map(doubler).AndThen().ParallelToStream(7, WorkStealing).AndThen().ToSlice())
+	assert.Equal(t, expected, doParallelChunks(input, Of().Map(doubler).transform, 3, 7))
+	assert.Equal(t, []interface{}{}, doParallelChunks([]interface{}{}, Of().Map(doubler).transform, 3, 7))
+
+	// Expensive elements
+	assert.Equal(t, expected, Of(input...).Map(slow).AndThen().Parallel().AndThen().ToSlice())
+	assert.Equal(t, expected, doParallelAdaptive(goiter.OfElements(input), Of().Map(slow).transform, nil, 4))
+
+	func() {
+		defer func() {
+			assert.Equal(t, ErrInfiniteFinisher, recover())
+		}()
+
+		Iterate(1, func(element interface{}) interface{} { return element }).AndThen().Parallel()
+		assert.Fail(t, "Must panic")
+	}()
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"io"
+	"text/template"
+)
+
+// RenderTemplate executes the template once for each element, with the element as the data, writing the output of
+// each execution to w as it is produced.
+// Returns the first error executing the template or closing the Stream, in which case no further elements are read.
+// Panics if the Finisher is infinite.
+func (fin Finisher) RenderTemplate(w io.Writer, tmpl *template.Template) (err error) {
+	term := fin.terminal("RenderTemplate")
+	defer func() {
+		if closeErr := term.done(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	for it := term.Iter(); it.Next(); {
+		if err = tmpl.Execute(w, it.Value()); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
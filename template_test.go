@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	var (
+		tmpl = template.Must(template.New("t").Parse("{{.Name}}={{.Value}}\n"))
+		buf  = &strings.Builder{}
+	)
+
+	type pair struct {
+		Name  string
+		Value int
+	}
+
+	assert.Nil(t, Of(pair{"a", 1}, pair{"b", 2}).AndThen().RenderTemplate(buf, tmpl))
+	assert.Equal(t, "a=1\nb=2\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, Of().AndThen().RenderTemplate(buf, tmpl))
+	assert.Equal(t, "", buf.String())
+
+	// Execution error stops reading elements
+	buf.Reset()
+	assert.NotNil(t, Of(pair{"a", 1}, 2, pair{"c", 3}).AndThen().RenderTemplate(buf, tmpl))
+	assert.Equal(t, "a=1\n", buf.String())
+
+	// Close error
+	assert.EqualError(
+		t,
+		Of().OnClose(func() error { return errors.New("close") }).AndThen().RenderTemplate(buf, tmpl),
+		"close",
+	)
+}
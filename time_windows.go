@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/bantling/goiter"
+)
+
+// TimeBucket is a bucket of elements whose timestamps fall into the same time window, as returned by
+// Finisher.GroupByTimeBucketOrdered
+type TimeBucket struct {
+	Start    time.Time
+	Elements []interface{}
+}
+
+// GroupByTimeBucket groups elements into buckets of the given duration by their timestamps, such as for per minute or
+// per hour rollups. The map key is the start of each bucket, and the elements of each bucket are in stream order.
+// Buckets are aligned to multiples of the duration since the zero time, as computed by time.Time.Truncate, and keys are
+// in the location of the timestamps, so timestamps should all be in the same location.
+// Unlike GroupByTimeBucketOrdered, the elements can be in any order.
+// Panics if bucket <= 0.
+// Panics if the Finisher is infinite.
+func (fin Finisher) GroupByTimeBucket(
+	ts func(element interface{}) time.Time,
+	bucket time.Duration,
+) map[time.Time][]interface{} {
+	if bucket <= 0 {
+		panic("bucket must be > 0")
+	}
+
+	term := fin.terminal("GroupByTimeBucket")
+	defer term.done()
+
+	m := map[time.Time][]interface{}{}
+
+	for it := term.Iter(); it.Next(); {
+		element := it.Value()
+		start := ts(element).Truncate(bucket)
+		m[start] = append(m[start], element)
+	}
+
+	return m
+}
+
+// GroupByTimeBucketOrdered is a streaming version of GroupByTimeBucket for elements that are ordered by timestamp, which
+// returns a new Finisher of TimeBucket.
+// Each bucket is produced as soon as an element of a later bucket is read, so only one bucket is buffered at a time, and
+// the Finisher may be infinite. See WindowByTime for the handling of elements that are out of order.
+// Panics if bucket <= 0.
+func (fin Finisher) GroupByTimeBucketOrdered(ts func(element interface{}) time.Time, bucket time.Duration) Finisher {
+	if bucket <= 0 {
+		panic("bucket must be > 0")
+	}
+
+	return fin.WindowByTime(ts, bucket).Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if it.Next() {
+						window := it.Value().([]interface{})
+						return TimeBucket{Start: ts(window[0]).Truncate(bucket), Elements: window}, true
+					}
+
+					return nil, false
+				},
+			)
+		},
+	)
+}
+
+// Session is a group of elements with the same key whose timestamps are separated by no more than a gap, as returned by
+// Finisher.Sessionize
+type Session struct {
+	Key      interface{}
+	Start    time.Time
+	End      time.Time
+	Elements []interface{}
+}
+
+// Sessionize returns a new Finisher of Session, where each session contains the elements of one key that are separated
+// by no more than the given gap of inactivity. An element more than gap after the previous element of its key starts a
+// new session for that key. If key is nil, all elements have the same nil key.
+//
+// Elements are expected to be ordered by timestamp. A session is produced as soon as an element of any key is read that
+// is more than gap after the end of the session, so only open sessions are buffered, and the Finisher may be infinite.
+// When the elements are exhausted, the remaining open sessions are produced.
+// Sessions produced at the same time are in the order they started.
+// Panics if gap <= 0.
+func (fin Finisher) Sessionize(
+	ts func(element interface{}) time.Time,
+	gap time.Duration,
+	key func(element interface{}) interface{},
+) Finisher {
+	if gap <= 0 {
+		panic("gap must be > 0")
+	}
+
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				open   []*Session
+				byKey  = map[interface{}]*Session{}
+				closed []*Session
+				done   bool
+			)
+
+			// closeSessions moves the open sessions that end before the given time, or all sessions if all is true,
+			// to closed
+			closeSessions := func(before time.Time, all bool) {
+				stillOpen := open[:0]
+				for _, session := range open {
+					if all || before.Sub(session.End) > gap {
+						closed = append(closed, session)
+						delete(byKey, session.Key)
+					} else {
+						stillOpen = append(stillOpen, session)
+					}
+				}
+
+				open = stillOpen
+			}
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					for (len(closed) == 0) && (!done) {
+						if !it.Next() {
+							done = true
+							closeSessions(time.Time{}, true)
+							break
+						}
+
+						var (
+							element = it.Value()
+							t       = ts(element)
+							k       interface{}
+						)
+
+						if key != nil {
+							k = key(element)
+						}
+
+						closeSessions(t, false)
+
+						if session, haveSession := byKey[k]; haveSession {
+							session.Elements = append(session.Elements, element)
+							if t.After(session.End) {
+								session.End = t
+							}
+						} else {
+							session = &Session{Key: k, Start: t, End: t, Elements: []interface{}{element}}
+							byKey[k] = session
+							open = append(open, session)
+						}
+					}
+
+					if len(closed) == 0 {
+						return nil, false
+					}
+
+					session := closed[0]
+					closed = closed[1:]
+
+					return *session, true
+				},
+			)
+		},
+	)
+}
+
+// eventTimeElement is an element buffered by OrderByEventTime, where seq is the order it was read in
+type eventTimeElement struct {
+	element interface{}
+	t       time.Time
+	seq     uint64
+}
+
+// eventTimeHeap is a min heap of eventTimeElement by time, then by the order elements were read in
+type eventTimeHeap []eventTimeElement
+
+func (h eventTimeHeap) Len() int { return len(h) }
+
+func (h eventTimeHeap) Less(i, j int) bool {
+	if h[i].t.Equal(h[j].t) {
+		return h[i].seq < h[j].seq
+	}
+
+	return h[i].t.Before(h[j].t)
+}
+
+func (h eventTimeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *eventTimeHeap) Push(x interface{}) { *h = append(*h, x.(eventTimeElement)) }
+
+func (h *eventTimeHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return x
+}
+
+// OrderByEventTime returns a new Finisher that corrects the order of elements whose timestamps are nearly sorted, by
+// buffering elements until they are no more than maxLateness older than the latest timestamp read, and emitting them in
+// timestamp order. Elements with the same timestamp are emitted in the order they were read.
+//
+// An element that is more than maxLateness older than the latest timestamp read when it arrives is late, since an element
+// with a later timestamp may already have been emitted. A late element is passed to the optional late function, if
+// provided, and is otherwise dropped.
+//
+// Only the elements within the lateness window are buffered, so the Finisher may be infinite.
+// When the elements are exhausted, the remaining buffered elements are emitted.
+// Panics if maxLateness < 0.
+func (fin Finisher) OrderByEventTime(
+	ts func(element interface{}) time.Time,
+	maxLateness time.Duration,
+	late ...func(element interface{}),
+) Finisher {
+	if maxLateness < 0 {
+		panic("maxLateness must be >= 0")
+	}
+
+	return fin.Transform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				buffered  eventTimeHeap
+				latest    time.Time
+				seq       uint64
+				exhausted bool
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					for {
+						// Emit the earliest element if it is at or before the watermark, or all elements are read
+						if (len(buffered) > 0) && (exhausted || (!buffered[0].t.After(latest.Add(-maxLateness)))) {
+							return heap.Pop(&buffered).(eventTimeElement).element, true
+						}
+
+						if exhausted || (!it.Next()) {
+							exhausted = true
+							if len(buffered) == 0 {
+								return nil, false
+							}
+
+							continue
+						}
+
+						var (
+							element = it.Value()
+							t       = ts(element)
+						)
+
+						if (seq > 0) && t.Before(latest.Add(-maxLateness)) {
+							if len(late) > 0 {
+								late[0](element)
+							}
+
+							continue
+						}
+
+						if (seq == 0) || t.After(latest) {
+							latest = t
+						}
+
+						heap.Push(&buffered, eventTimeElement{element: element, t: t, seq: seq})
+						seq++
+					}
+				},
+			)
+		},
+	)
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testBaseTime = time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	testExtract  = func(element interface{}) time.Time { return element.(time.Time) }
+)
+
+// testAt returns the test base time plus the given number of seconds
+func testAt(seconds int) time.Time {
+	return testBaseTime.Add(time.Duration(seconds) * time.Second)
+}
+
+func TestStreamGroupByTimeBucket(t *testing.T) {
+	assert.Equal(t, map[time.Time][]interface{}{}, Of().AndThen().GroupByTimeBucket(testExtract, time.Minute))
+
+	assert.Equal(
+		t,
+		map[time.Time][]interface{}{
+			testAt(0):   {testAt(0), testAt(59), testAt(30)},
+			testAt(60):  {testAt(60)},
+			testAt(180): {testAt(185), testAt(239)},
+		},
+		Of(testAt(0), testAt(59), testAt(60), testAt(185), testAt(239), testAt(30)).
+			AndThen().
+			GroupByTimeBucket(testExtract, time.Minute),
+	)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "bucket must be > 0", recover())
+		}()
+
+		Of().AndThen().GroupByTimeBucket(testExtract, 0)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamGroupByTimeBucketOrdered(t *testing.T) {
+	assert.Equal(t, []interface{}{}, Of().AndThen().GroupByTimeBucketOrdered(testExtract, time.Minute).ToSlice())
+
+	assert.Equal(
+		t,
+		[]interface{}{
+			TimeBucket{Start: testAt(0), Elements: []interface{}{testAt(0), testAt(59)}},
+			TimeBucket{Start: testAt(60), Elements: []interface{}{testAt(60)}},
+			TimeBucket{Start: testAt(180), Elements: []interface{}{testAt(185), testAt(239)}},
+		},
+		Of(testAt(0), testAt(59), testAt(60), testAt(185), testAt(239)).
+			AndThen().
+			GroupByTimeBucketOrdered(testExtract, time.Minute).
+			ToSlice(),
+	)
+
+	// Infinite
+	assert.Equal(
+		t,
+		[]interface{}{
+			TimeBucket{Start: testAt(0), Elements: []interface{}{testAt(0), testAt(30)}},
+			TimeBucket{Start: testAt(60), Elements: []interface{}{testAt(60), testAt(90)}},
+		},
+		Iterate(testAt(-30), func(element interface{}) interface{} { return element.(time.Time).Add(30 * time.Second) }).
+			AndThen().
+			GroupByTimeBucketOrdered(testExtract, time.Minute).
+			Limit(2).
+			ToSlice(),
+	)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "bucket must be > 0", recover())
+		}()
+
+		Of().AndThen().GroupByTimeBucketOrdered(testExtract, 0)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamSessionize(t *testing.T) {
+	type event struct {
+		user string
+		at   time.Time
+	}
+
+	var (
+		ts   = func(element interface{}) time.Time { return element.(event).at }
+		user = func(element interface{}) interface{} { return element.(event).user }
+		a0   = event{"a", testAt(0)}
+		b10  = event{"b", testAt(10)}
+		a40  = event{"a", testAt(40)}
+		b100 = event{"b", testAt(100)}
+		a110 = event{"a", testAt(110)}
+		a140 = event{"a", testAt(140)}
+	)
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().Sessionize(ts, time.Minute, user).ToSlice())
+
+	assert.Equal(
+		t,
+		[]interface{}{
+			// b100 closes b's first session, and a110 closes a's first session
+			Session{Key: "b", Start: testAt(10), End: testAt(10), Elements: []interface{}{b10}},
+			Session{Key: "a", Start: testAt(0), End: testAt(40), Elements: []interface{}{a0, a40}},
+			// Remaining open sessions
+			Session{Key: "b", Start: testAt(100), End: testAt(100), Elements: []interface{}{b100}},
+			Session{Key: "a", Start: testAt(110), End: testAt(140), Elements: []interface{}{a110, a140}},
+		},
+		Of(a0, b10, a40, b100, a110, a140).AndThen().Sessionize(ts, time.Minute, user).ToSlice(),
+	)
+
+	// Nil key, where a gap equal to the limit continues the session
+	assert.Equal(
+		t,
+		[]interface{}{
+			Session{Start: testAt(0), End: testAt(100), Elements: []interface{}{a0, b10, a40, b100}},
+			Session{Start: testAt(200), End: testAt(200), Elements: []interface{}{event{"c", testAt(200)}}},
+		},
+		Of(a0, b10, a40, b100, event{"c", testAt(200)}).AndThen().Sessionize(ts, time.Minute, nil).ToSlice(),
+	)
+
+	// Each iteration of a replayable Stream starts with no sessions
+	fin := Of(a0, a40).Cache().AndThen().Sessionize(ts, time.Minute, user)
+	for i := 0; i < 2; i++ {
+		assert.Equal(
+			t,
+			[]interface{}{Session{Key: "a", Start: testAt(0), End: testAt(40), Elements: []interface{}{a0, a40}}},
+			fin.ToSlice(),
+		)
+	}
+
+	func() {
+		defer func() {
+			assert.Equal(t, "gap must be > 0", recover())
+		}()
+
+		Of().AndThen().Sessionize(ts, 0, user)
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestStreamOrderByEventTime(t *testing.T) {
+	var late []interface{}
+	onLate := func(element interface{}) { late = append(late, element) }
+
+	assert.Equal(t, []interface{}{}, Of().AndThen().OrderByEventTime(testExtract, time.Second).ToSlice())
+
+	assert.Equal(
+		t,
+		[]interface{}{testAt(0), testAt(3), testAt(5), testAt(12), testAt(15), testAt(20), testAt(25), testAt(40)},
+		Of(testAt(0), testAt(5), testAt(3), testAt(20), testAt(12), testAt(8), testAt(25), testAt(15), testAt(40)).
+			AndThen().
+			OrderByEventTime(testExtract, 10*time.Second, onLate).
+			ToSlice(),
+	)
+	assert.Equal(t, []interface{}{testAt(8)}, late)
+
+	// Late elements are dropped without a late function, and equal timestamps keep their order
+	type event struct {
+		id int
+		at time.Time
+	}
+	ts := func(element interface{}) time.Time { return element.(event).at }
+
+	assert.Equal(
+		t,
+		[]interface{}{event{1, testAt(0)}, event{2, testAt(1)}, event{3, testAt(1)}, event{5, testAt(9)}},
+		Of(event{2, testAt(1)}, event{1, testAt(0)}, event{3, testAt(1)}, event{5, testAt(9)}, event{6, testAt(0)}).
+			AndThen().
+			OrderByEventTime(ts, 2*time.Second).
+			ToSlice(),
+	)
+
+	// Each iteration of a replayable Stream starts with an empty buffer
+	fin := Of(testAt(5), testAt(3)).Cache().AndThen().OrderByEventTime(testExtract, 10*time.Second)
+	assert.Equal(t, []interface{}{testAt(3), testAt(5)}, fin.ToSlice())
+	assert.Equal(t, []interface{}{testAt(3), testAt(5)}, fin.ToSlice())
+
+	// Infinite, in reverse order within each pair
+	var n int
+	assert.Equal(
+		t,
+		[]interface{}{testAt(0), testAt(1), testAt(2), testAt(3)},
+		Iterate(testAt(1), func(interface{}) interface{} {
+			n++
+			if n%2 == 1 {
+				return testAt(n - 1)
+			}
+			return testAt(n + 1)
+		}).
+			AndThen().
+			OrderByEventTime(testExtract, time.Second).
+			Limit(4).
+			ToSlice(),
+	)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "maxLateness must be >= 0", recover())
+		}()
+
+		Of().AndThen().OrderByEventTime(testExtract, -1)
+		assert.Fail(t, "Must panic")
+	}()
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"github.com/bantling/goiter"
+)
+
+// Pair is an element made of two values, as produced by Zip and Pairwise, and by Join when PairOf is the combiner
+type Pair struct {
+	First  interface{}
+	Second interface{}
+}
+
+// Triple is an element made of three values
+type Triple struct {
+	First  interface{}
+	Second interface{}
+	Third  interface{}
+}
+
+// PairOf returns a Pair of the given values, for use as the combiner of Join
+func PairOf(first, second interface{}) interface{} {
+	return Pair{First: first, Second: second}
+}
+
+// TripleOf returns a Triple of the given values
+func TripleOf(first, second, third interface{}) interface{} {
+	return Triple{First: first, Second: second, Third: third}
+}
+
+// PairFirst maps a Pair element to its first value, for use with Map, or as a key for Comparing, GroupBy, and Join.
+// Panics if the element is not a Pair.
+func PairFirst(element interface{}) interface{} {
+	return element.(Pair).First
+}
+
+// PairSecond maps a Pair element to its second value, for use with Map, or as a key for Comparing, GroupBy, and Join.
+// Panics if the element is not a Pair.
+func PairSecond(element interface{}) interface{} {
+	return element.(Pair).Second
+}
+
+// TripleFirst maps a Triple element to its first value, for use with Map, or as a key for Comparing, GroupBy, and Join.
+// Panics if the element is not a Triple.
+func TripleFirst(element interface{}) interface{} {
+	return element.(Triple).First
+}
+
+// TripleSecond maps a Triple element to its second value, for use with Map, or as a key for Comparing, GroupBy, and
+// Join.
+// Panics if the element is not a Triple.
+func TripleSecond(element interface{}) interface{} {
+	return element.(Triple).Second
+}
+
+// TripleThird maps a Triple element to its third value, for use with Map, or as a key for Comparing, GroupBy, and Join.
+// Panics if the element is not a Triple.
+func TripleThird(element interface{}) interface{} {
+	return element.(Triple).Third
+}
+
+// ComparingPairs returns a Comparator of Pair elements that compares the first values with firstLess, and then the
+// second values with secondLess if neither first value is less than the other.
+// The Comparator panics if an element is not a Pair.
+func ComparingPairs(firstLess, secondLess func(value1, value2 interface{}) bool) Comparator {
+	return Comparing(PairFirst, firstLess).ThenComparing(Comparing(PairSecond, secondLess))
+}
+
+// Zip returns a Stream of Pair elements of the elements of s1 and s2 at the same position.
+// The Stream is finite if either Stream is finite, and closing it closes both Streams.
+// If exactly one Stream is infinite, the Stream ends when the finite Stream is exhausted.
+// Otherwise, the Stream panics with ErrZipLengths if one Stream is exhausted before the other.
+func Zip(s1, s2 Stream) Stream {
+	return zipPairs(s1, s2, PairOf)
+}
+
+// Pairwise returns a Stream of a Pair of each element and the element after it, so a Stream of n elements results in
+// n - 1 Pairs, such as to compute the differences between adjacent elements.
+// The Stream is iterated sequentially by the parallel methods of Finisher, and only transforms applied after
+// Pairwise are executed in parallel.
+func Pairwise(s Stream) Stream {
+	return s.sequentialTransform(
+		func(it *goiter.Iter) *goiter.Iter {
+			var (
+				previous     interface{}
+				havePrevious bool
+			)
+
+			return goiter.NewIter(
+				func() (interface{}, bool) {
+					if !havePrevious {
+						if !it.Next() {
+							return nil, false
+						}
+
+						previous = it.Value()
+						havePrevious = true
+					}
+
+					if !it.Next() {
+						return nil, false
+					}
+
+					val := Pair{First: previous, Second: it.Value()}
+					previous = val.Second
+
+					return val, true
+				},
+			)
+		},
+		false,
+	)
+}
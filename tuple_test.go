@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package gostream
+
+import (
+	"testing"
+
+	"github.com/bantling/gofuncs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPairTriple(t *testing.T) {
+	p := PairOf(1, "a")
+	assert.Equal(t, Pair{First: 1, Second: "a"}, p)
+	assert.Equal(t, 1, PairFirst(p))
+	assert.Equal(t, "a", PairSecond(p))
+
+	tr := TripleOf(1, "a", true)
+	assert.Equal(t, Triple{First: 1, Second: "a", Third: true}, tr)
+	assert.Equal(t, 1, TripleFirst(tr))
+	assert.Equal(t, "a", TripleSecond(tr))
+	assert.Equal(t, true, TripleThird(tr))
+
+	// Key extractor
+	assert.Equal(
+		t,
+		map[interface{}][]interface{}{"a": {Pair{1, "a"}, Pair{3, "a"}}, "b": {Pair{2, "b"}}},
+		Of(Pair{1, "a"}, Pair{2, "b"}, Pair{3, "a"}).AndThen().GroupBy(PairSecond),
+	)
+
+	// Comparator
+	assert.Equal(
+		t,
+		[]interface{}{Pair{1, 2}, Pair{1, 3}, Pair{2, 1}},
+		Of(Pair{2, 1}, Pair{1, 3}, Pair{1, 2}).AndThen().Sorted(ComparingPairs(gofuncs.IntSortFunc, gofuncs.IntSortFunc)).ToSlice(),
+	)
+	assert.Equal(
+		t,
+		[]interface{}{Triple{2, 1, 0}, Triple{1, 3, 0}},
+		Of(Triple{1, 3, 0}, Triple{2, 1, 0}).AndThen().Sorted(Comparing(TripleSecond, gofuncs.IntSortFunc)).ToSlice(),
+	)
+
+	// Join
+	assert.Equal(
+		t,
+		[]interface{}{Pair{Pair{1, "a"}, Pair{1, "x"}}},
+		Join(Of(Pair{1, "a"}, Pair{2, "b"}), Of(Pair{1, "x"}), PairFirst, PairFirst, PairOf).AndThen().ToSlice(),
+	)
+}
+
+func TestZip(t *testing.T) {
+	assert.Equal(t, []interface{}{}, Zip(Of(), Of()).AndThen().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{Pair{1, "a"}, Pair{2, "b"}},
+		Zip(Of(1, 2), Of("a", "b")).AndThen().ToSlice(),
+	)
+
+	// A finite Stream zipped with an infinite Stream ends with the finite Stream
+	inc := func(element interface{}) interface{} { return element.(int) + 1 }
+	assert.Equal(
+		t,
+		[]interface{}{Pair{"a", 1}, Pair{"b", 2}},
+		Zip(Of("a", "b"), Iterate(0, inc)).AndThen().ToSlice(),
+	)
+	assert.Equal(
+		t,
+		[]interface{}{Pair{1, "a"}},
+		Zip(Iterate(0, inc), Of("a")).AndThen().ToSlice(),
+	)
+	assert.Equal(t, []interface{}{}, Zip(Iterate(0, inc), Of()).AndThen().ToSlice())
+
+	func() {
+		defer func() {
+			assert.Equal(t, ErrZipLengths, recover())
+		}()
+
+		Zip(Of(1, 2), Of("a")).AndThen().ToSlice()
+		assert.Fail(t, "Must panic")
+	}()
+}
+
+func TestPairwise(t *testing.T) {
+	assert.Equal(t, []interface{}{}, Pairwise(Of()).AndThen().ToSlice())
+	assert.Equal(t, []interface{}{}, Pairwise(Of(1)).AndThen().ToSlice())
+	assert.Equal(
+		t,
+		[]interface{}{Pair{1, 2}, Pair{2, 4}, Pair{4, 7}},
+		Pairwise(Of(1, 2, 4, 7)).AndThen().ToSlice(),
+	)
+
+	// Differences between adjacent elements, computed in parallel
+	assert.Equal(
+		t,
+		[]int{1, 2, 3},
+		Pairwise(Of(1, 2, 4, 7)).
+			Map(func(element interface{}) interface{} {
+				return element.(Pair).Second.(int) - element.(Pair).First.(int)
+			}).
+			AndThen().
+			ParallelToSliceOf(0, 1),
+	)
+
+	// Infinite Stream is still infinite
+	assert.Equal(
+		t,
+		[]interface{}{Pair{1, 2}, Pair{2, 3}},
+		Pairwise(Iterate(0, func(element interface{}) interface{} { return element.(int) + 1 })).AndThen().Limit(2).ToSlice(),
+	)
+
+	// Each iteration of a replayable Stream starts over
+	cached := Pairwise(Of(1, 2, 3).Cache())
+	assert.Equal(t, []interface{}{Pair{1, 2}, Pair{2, 3}}, cached.AndThen().ToSlice())
+	assert.Equal(t, []interface{}{Pair{1, 2}, Pair{2, 3}}, cached.AndThen().ToSlice())
+
+	// An iteration of a replayable Stream starts over after a partial iteration
+	snapshot := Pairwise(Of(1, 2, 3).Snapshot())
+	it := snapshot.Iter()
+	assert.True(t, it.Next())
+	assert.True(t, it.Next())
+	assert.Equal(t, []interface{}{Pair{1, 2}, Pair{2, 3}}, snapshot.AndThen().ToSlice())
+
+	// FindFirst and a later terminal read the same iteration
+	fin := Pairwise(Of(1, 2, 3, 4)).AndThen()
+	assert.Equal(t, Pair{1, 2}, fin.FindFirst().MustGet())
+	assert.Equal(t, []interface{}{Pair{2, 3}, Pair{3, 4}}, fin.ToSlice())
+}